@@ -0,0 +1,79 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/hectorchu/gonano/rpc"
+)
+
+type cachedAccount struct {
+	balance, pending *big.Int
+	frontier         rpc.BlockHash
+	updated          time.Time
+}
+
+// saveCachedAccounts records the last known state of accounts. A nil
+// frontier leaves the stored frontier unchanged.
+func saveCachedAccounts(accounts map[string]*cachedAccount) error {
+	return withAppDB(func(db *sql.DB) (err error) {
+		tx, err := db.Begin()
+		if err != nil {
+			return
+		}
+		defer tx.Rollback()
+		for address, ca := range accounts {
+			var frontier string
+			if ca.frontier != nil {
+				frontier = ca.frontier.String()
+			}
+			if _, err = tx.Exec(`INSERT INTO account_cache (address, balance, pending, frontier, updated)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (address) DO UPDATE SET
+				balance = excluded.balance, pending = excluded.pending, updated = excluded.updated,
+				frontier = CASE WHEN excluded.frontier = '' THEN frontier ELSE excluded.frontier END`,
+				address, ca.balance.String(), ca.pending.String(), frontier, ca.updated.Unix(),
+			); err != nil {
+				return
+			}
+		}
+		return tx.Commit()
+	})
+}
+
+func loadCachedAccounts(addresses []string) (accounts map[string]*cachedAccount, err error) {
+	accounts = make(map[string]*cachedAccount)
+	err = withAppDB(func(db *sql.DB) (err error) {
+		stmt, err := db.Prepare("SELECT balance, pending, frontier, updated FROM account_cache WHERE address = ?")
+		if err != nil {
+			return
+		}
+		defer stmt.Close()
+		for _, address := range addresses {
+			var (
+				balance, pending, frontier string
+				updated                    int64
+			)
+			if err = stmt.QueryRow(address).Scan(&balance, &pending, &frontier, &updated); err == sql.ErrNoRows {
+				continue
+			} else if err != nil {
+				return
+			}
+			ca := &cachedAccount{
+				balance: new(big.Int),
+				pending: new(big.Int),
+				updated: time.Unix(updated, 0),
+			}
+			ca.balance.SetString(balance, 10)
+			ca.pending.SetString(pending, 10)
+			if frontier != "" {
+				ca.frontier, _ = hex.DecodeString(frontier)
+			}
+			accounts[address] = ca
+		}
+		return nil
+	})
+	return
+}
@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"sync"
+	"time"
 
 	"fyne.io/fyne"
 	"fyne.io/fyne/container"
@@ -29,10 +29,13 @@ type accountList struct {
 	receiveAllButton          *widget.Button
 	changeRepButton           *widget.Button
 	tokensButton              *widget.Button
+	historyButton             *widget.Button
+	toolsButton               *contextMenuButton
 	toggleThemeButton         *widget.Button
 	wl                        *walletList
 	wi                        *walletInfo
 	selectedAccount           *accountInfo
+	pendingRequest            *paymentRequest
 }
 
 func newAccountList(win fyne.Window) (al *accountList) {
@@ -46,7 +49,8 @@ func newAccountList(win fyne.Window) (al *accountList) {
 			},
 			func() fyne.CanvasObject {
 				return fyne.NewContainerWithLayout(
-					newHBoxLayout([]int{600}), newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+					newHBoxLayout([]int{600, 200}), newCopyableLabel(win, ""),
+					newCopyableLabel(win, ""), newCopyableLabel(win, ""),
 				)
 			},
 			func(id widget.ListItemID, item fyne.CanvasObject) {
@@ -62,17 +66,49 @@ func newAccountList(win fyne.Window) (al *accountList) {
 				if ai.pending.Raw != nil && ai.pending.Raw.Sign() > 0 {
 					balance += fmt.Sprintf(" (+ %s)", ai.pending)
 				}
+				if !ai.updated.IsZero() {
+					balance += ai.updated.Local().Format(" (as of Jan 2 15:04)")
+				}
 				al.m.Unlock()
 				getLabel := func(i int) *contextMenuLabel {
 					return item.(*fyne.Container).Objects[i].(*contextMenuLabel)
 				}
 				getLabel(0).SetText(ai.address)
-				getLabel(1).SetText(balance)
+				getLabel(1).SetText(ai.Label)
+				getLabel(2).SetText(balance)
+				menu := fyne.NewMenu("",
+					fyne.NewMenuItem("Copy", func() { win.Clipboard().SetContent(ai.address) }),
+					fyne.NewMenuItem("Edit label", func() { al.showEditLabelDialog(win, ai) }),
+					fyne.NewMenuItem("History", func() { newHistoryList(ai) }),
+					fyne.NewMenuItem("Pending blocks", func() { newPendingList(al.wi, ai) }),
+					fyne.NewMenuItem("Receive details", func() { showReceiveDialog(win, ai) }),
+				)
+				if !al.wi.IsWatchOnly {
+					menu.Items = append(menu.Items, fyne.NewMenuItem("Minimum receive amount", func() {
+						showReceiveMinimumDialog(win, al.wl, al.wi, ai)
+					}))
+				} else {
+					menu.Items = append(menu.Items,
+						fyne.NewMenuItem("Prepare offline send", func() {
+							showPrepareOfflineDialog(win, ai, "send")
+						}),
+						fyne.NewMenuItem("Prepare offline rep change", func() {
+							showPrepareOfflineDialog(win, ai, "change")
+						}),
+					)
+				}
+				getLabel(0).menu = menu
+				getLabel(1).menu = menu
 				getLabel(0).tapped = func() { al.list.Select(id) }
 				getLabel(1).tapped = func() { al.list.Select(id) }
+				getLabel(2).tapped = func() { al.list.Select(id) }
 			},
 		),
 		addButton: widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() {
+			if al.wi != nil && al.wi.IsWatchOnly {
+				al.showAddWatchAccountDialog(win)
+				return
+			}
 			if err := al.addAccount(); err != nil {
 				dialog.ShowError(err, win)
 			}
@@ -83,7 +119,7 @@ func newAccountList(win fyne.Window) (al *accountList) {
 			}
 		}),
 		sendButton: widget.NewButtonWithIcon("Send", theme.MailForwardIcon(), func() {
-			al.showSendDialog(win)
+			al.showSendDialog(win, nil)
 		}),
 		receiveButton: widget.NewButtonWithIcon("Receive", theme.MailReplyIcon(), func() {
 			if err := al.receive(win); err != nil {
@@ -108,6 +144,33 @@ func newAccountList(win fyne.Window) (al *accountList) {
 				newTokenList(al.wi, al.selectedAccount)
 			},
 		),
+		historyButton: widget.NewButtonWithIcon("History", theme.HistoryIcon(), func() {
+			if al.selectedAccount == nil {
+				return
+			}
+			newHistoryList(al.selectedAccount)
+		}),
+		toolsButton: newContextMenuButton("Tools", theme.SettingsIcon(), fyne.NewMenu("",
+			fyne.NewMenuItem("Address book", func() { newAddressBookList() }),
+			fyne.NewMenuItem("RPC nodes", func() { newNodeListWindow() }),
+			fyne.NewMenuItem("Proof of work", func() { showWorkSettingsDialog(win) }),
+			fyne.NewMenuItem("Pending outbound", func() { newOutboundWindow() }),
+			fyne.NewMenuItem("Sign offline request", func() {
+				if al.wi == nil || al.wi.IsWatchOnly {
+					dialog.ShowError(errors.New("Select a wallet to sign with"), win)
+					return
+				}
+				al.showSignOfflineDialog(win)
+			}),
+			fyne.NewMenuItem("Publish signed block", func() { showPublishSignedDialog(win) }),
+			fyne.NewMenuItem("Batch send", func() {
+				if al.selectedAccount == nil || al.wi.IsWatchOnly {
+					dialog.ShowError(errors.New("Select an account to send from"), win)
+					return
+				}
+				newBatchSend(al, al.wi, al.selectedAccount)
+			}),
+		)),
 		toggleThemeButton: widget.NewButtonWithIcon("", toggleThemeResource(), func() {
 			toggleTheme()
 			al.toggleThemeButton.SetIcon(toggleThemeResource())
@@ -118,33 +181,49 @@ func newAccountList(win fyne.Window) (al *accountList) {
 		widget.NewHBox(
 			al.addButton, al.removeButton, al.sendButton,
 			al.receiveButton, al.receiveAllButton, al.changeRepButton,
-			al.tokensButton, layout.NewSpacer(), al.toggleThemeButton,
+			al.tokensButton, al.historyButton, layout.NewSpacer(),
+			al.toolsButton, al.toggleThemeButton,
 		),
 		nil, nil, al.list,
 	)
-	al.list.OnSelected = func(id widget.ListItemID) { al.setAccount(al.wi.accountsList[id]) }
+	al.list.OnSelected = func(id widget.ListItemID) {
+		al.setAccount(al.wi.accountsList[id])
+		if pr := al.pendingRequest; pr != nil && !al.wi.IsWatchOnly {
+			al.pendingRequest = nil
+			al.showSendDialog(win, pr)
+		}
+	}
 	al.list.OnUnselected = func(id widget.ListItemID) { al.setAccount(nil) }
 	al.setWallet(nil)
-	wsClient.subscribe(func(block *rpc.Block) {
-		al.m.Lock()
-		if al.wi != nil {
-			if al.wi.updateBalance(block.Account) {
-				defer al.list.Refresh()
+	bus.subscribe(func(e interface{}) {
+		switch e := e.(type) {
+		case blockConfirmedEvent:
+			al.m.Lock()
+			precache := al.wi != nil && !al.wi.IsWatchOnly && al.wi.Accounts[e.block.Account] != nil
+			al.m.Unlock()
+			if precache {
+				works.precache(e.block.Account, e.hash)
+			}
+			if al.hasAccount(e.block.Account) {
+				publishBalance(e.block.Account, e.hash)
+			}
+			if al.hasAccount(e.block.LinkAsAccount) {
+				publishBalance(e.block.LinkAsAccount, nil)
 			}
-			if _, ok := al.wi.Accounts[block.LinkAsAccount]; ok {
-				go func() {
-					al.m.Lock()
-					if al.wi != nil {
-						if al.wi.updateBalance(block.LinkAsAccount) {
-							defer al.list.Refresh()
-						}
-					}
-					al.m.Unlock()
-				}()
+		case balanceChangedEvent:
+			al.m.Lock()
+			if al.wi != nil {
+				if ai, ok := al.wi.Accounts[e.account]; ok {
+					ai.balance, ai.pending = e.balance, e.pending
+					ai.updated = time.Time{}
+					defer al.list.Refresh()
+				}
 			}
+			al.m.Unlock()
 		}
-		al.m.Unlock()
 	})
+	wsClient.onReconnect(al.resync)
+	nodes.onOnline(al.resync)
 	return
 }
 
@@ -168,10 +247,11 @@ func (al *accountList) setWallet(wi *walletInfo) {
 		al.receiveAllButton.Disable()
 		al.changeRepButton.Disable()
 		al.tokensButton.Disable()
+		al.historyButton.Disable()
 		al.setAccount(nil)
 	} else {
 		al.addButton.Enable()
-		if len(wi.accountsList) > 0 {
+		if len(wi.accountsList) > 0 && !wi.IsWatchOnly {
 			al.receiveAllButton.Enable()
 		} else {
 			al.receiveAllButton.Disable()
@@ -179,14 +259,25 @@ func (al *accountList) setWallet(wi *walletInfo) {
 	}
 	al.list.Unselect(0)
 	al.list.Refresh()
-	go func() {
-		al.m.Lock()
-		if al.wi != nil {
-			al.wi.getBalances()
-		}
-		al.m.Unlock()
-		al.list.Refresh()
-	}()
+	go al.resync()
+}
+
+func (al *accountList) hasAccount(account string) (ok bool) {
+	al.m.Lock()
+	if al.wi != nil {
+		_, ok = al.wi.Accounts[account]
+	}
+	al.m.Unlock()
+	return
+}
+
+func (al *accountList) resync() {
+	al.m.Lock()
+	if al.wi != nil {
+		al.wi.getBalances()
+	}
+	al.m.Unlock()
+	al.list.Refresh()
 }
 
 func (al *accountList) setAccount(ai *accountInfo) {
@@ -197,12 +288,20 @@ func (al *accountList) setAccount(ai *accountInfo) {
 		al.receiveButton.Disable()
 		al.changeRepButton.Disable()
 		al.tokensButton.Disable()
+		al.historyButton.Disable()
 	} else {
 		al.removeButton.Enable()
-		al.sendButton.Enable()
-		al.receiveButton.Enable()
-		al.changeRepButton.Enable()
+		if al.wi.IsWatchOnly {
+			al.sendButton.Disable()
+			al.receiveButton.Disable()
+			al.changeRepButton.Disable()
+		} else {
+			al.sendButton.Enable()
+			al.receiveButton.Enable()
+			al.changeRepButton.Enable()
+		}
 		al.tokensButton.Enable()
+		al.historyButton.Enable()
 	}
 }
 
@@ -223,6 +322,66 @@ func (al *accountList) addAccount() (err error) {
 	return al.wl.saveWallet(al.wi)
 }
 
+func (al *accountList) showEditLabelDialog(win fyne.Window, ai *accountInfo) {
+	var (
+		label   = widget.NewEntry()
+		note    = widget.NewMultiLineEntry()
+		scroll  = container.NewHScroll(label)
+		scroll2 = container.NewScroll(note)
+		content = widget.NewForm(
+			widget.NewFormItem("Label", scroll),
+			widget.NewFormItem("Note", scroll2),
+		)
+	)
+	label.SetText(ai.Label)
+	note.SetText(ai.Note)
+	scroll.SetMinSize(fyne.NewSize(400, 0))
+	scroll2.SetMinSize(fyne.NewSize(400, 150))
+	dialog.ShowCustomConfirm("Edit label for "+ai.address, "OK", "Cancel", content, func(ok bool) {
+		if ok {
+			ai.Label, ai.Note = label.Text, note.Text
+			al.list.Refresh()
+			if err := al.wl.saveWallet(al.wi); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}
+	}, win)
+}
+
+func (al *accountList) showAddWatchAccountDialog(win fyne.Window) {
+	var (
+		account           = widget.NewEntry()
+		scroll            = container.NewHScroll(account)
+		errLabel, isValid = newAddressError()
+		content           = widget.NewForm(widget.NewFormItem("Address", container.NewVBox(scroll, errLabel)))
+	)
+	scroll.SetMinSize(fyne.NewSize(580, 0))
+	account.SetPlaceHolder("Address to watch")
+	d := newConfirmDialog("Watch account", "OK", "Cancel", content, func(ok bool) {
+		if ok {
+			if err := al.addWatchAccount(account.Text); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}
+	}, win)
+	account.OnChanged = func(s string) { d.setValid(isValid(s)) }
+	d.setValid(false)
+	d.Show()
+}
+
+func (al *accountList) addWatchAccount(address string) (err error) {
+	al.m.Lock()
+	if err = al.wi.addWatchAccount(address); err == nil {
+		al.wi.updateBalance(al.wi.accountsList[len(al.wi.accountsList)-1].address)
+	}
+	al.m.Unlock()
+	if err != nil {
+		return
+	}
+	al.list.Refresh()
+	return al.wl.saveWallet(al.wi)
+}
+
 func (al *accountList) removeAccount() (err error) {
 	if al.wi == nil || al.selectedAccount == nil {
 		return
@@ -244,8 +403,9 @@ func (al *accountList) removeAccount() (err error) {
 	return al.wl.saveWallet(al.wi)
 }
 
-func (al *accountList) showSendDialog(win fyne.Window) {
+func (al *accountList) showSendDialog(win fyne.Window, pr *paymentRequest) {
 	var (
+		request = pr
 		account = widget.NewEntry()
 		amount  = widget.NewEntry()
 		max     = widget.NewButton("Max", func() {
@@ -255,30 +415,106 @@ func (al *accountList) showSendDialog(win fyne.Window) {
 			}
 			al.m.Unlock()
 		})
-		paymentURL = widget.NewEntry()
-		scroll     = container.NewHScroll(amount)
-		content    = widget.NewForm(
-			widget.NewFormItem("Recipient", container.NewHScroll(account)),
+		memo     = widget.NewEntry()
+		contacts = widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+			showContactPicker(win, func(c *contact) {
+				account.SetText(c.Address)
+				if c.Amount != "" {
+					amount.SetText(c.Amount)
+				}
+				memo.SetText(c.Memo)
+			})
+		})
+		fill              func(*paymentRequest)
+		qrImport          = newQRImportButton(win, func(pr *paymentRequest) { fill(pr) })
+		errLabel, isValid = newAddressError()
+		paymentURL        = widget.NewEntry()
+		scroll            = container.NewHScroll(amount)
+		content           = widget.NewForm(
+			widget.NewFormItem("Recipient", container.NewVBox(container.NewBorder(
+				nil, nil, nil, container.NewHBox(qrImport, contacts), container.NewHScroll(account),
+			), errLabel)),
 			widget.NewFormItem("Amount", container.NewHBox(scroll, max)),
+			widget.NewFormItem("Memo", container.NewHScroll(memo)),
 			widget.NewFormItem("Payment URL", container.NewHScroll(paymentURL)),
 		)
 	)
+	fill = func(pr *paymentRequest) {
+		request = pr
+		account.SetText(pr.address)
+		if pr.amount != nil {
+			amount.SetText(rawToNano(pr.amount))
+		}
+		if pr.message != "" {
+			memo.SetText(pr.message)
+		} else if pr.label != "" {
+			memo.SetText(pr.label)
+		}
+	}
 	scroll.SetMinSize(fyne.NewSize(500, 0))
-	account.SetPlaceHolder("Address to send to")
+	account.SetPlaceHolder("Address or nano: payment URI to send to")
 	amount.SetPlaceHolder("Amount of NANO to send")
+	memo.SetPlaceHolder("Note kept in this wallet only (optional)")
 	paymentURL.SetPlaceHolder("URL to send block to (leave blank to send to network)")
-	dialog.ShowCustomConfirm(
+	d := newConfirmDialog(
 		"Send from "+al.selectedAccount.address, "OK", "Cancel", content, func(ok bool) {
-			if ok {
-				if err := al.send(win, account.Text, amount.Text, paymentURL.Text); err != nil {
+			if !ok {
+				return
+			}
+			to, err := validateAddress(account.Text)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			err = al.showSendReview(win, to, amount.Text, request, func() {
+				if err := al.send(win, to, amount.Text, memo.Text, paymentURL.Text); err != nil {
 					dialog.ShowError(err, win)
 				}
+			})
+			if err != nil {
+				dialog.ShowError(err, win)
 			}
 		}, win,
 	)
+	account.OnChanged = func(s string) {
+		if isPaymentURI(s) {
+			pr, err := parsePaymentURI(s)
+			if err != nil {
+				errLabel.SetText(err.Error())
+				errLabel.Show()
+				d.setValid(false)
+				return
+			}
+			fill(pr)
+			return
+		}
+		d.setValid(isValid(s))
+	}
+	if pr != nil {
+		fill(pr)
+	} else {
+		d.setValid(false)
+	}
+	d.Show()
 }
 
-func (al *accountList) send(win fyne.Window, account, amount, paymentURL string) (err error) {
+// openPaymentURI opens the send dialog for a nano: URI, waiting for an
+// account to be selected if necessary.
+func (al *accountList) openPaymentURI(win fyne.Window, uri string) {
+	pr, err := parsePaymentURI(uri)
+	if err != nil {
+		dialog.ShowError(err, win)
+		return
+	}
+	if al.selectedAccount == nil || al.wi.IsWatchOnly {
+		al.pendingRequest = pr
+		dialog.ShowInformation("Payment Request", "Select an account to pay from.", win)
+		return
+	}
+	al.showSendDialog(win, pr)
+}
+
+func (al *accountList) send(win fyne.Window, account, amount, memo, paymentURL string) (err error) {
 	n, err := util.NanoAmountFromString(amount)
 	if err != nil {
 		return
@@ -291,32 +527,43 @@ func (al *accountList) send(win fyne.Window, account, amount, paymentURL string)
 		return errors.New("Address mismatch")
 	}
 	var hash rpc.BlockHash
-	prog := dialog.NewProgressInfinite(al.wi.Label, "Generating block...", win)
-	prog.Show()
-	if paymentURL == "" {
-		hash, err = a.Send(account, n.Raw)
-		prog.Hide()
-		if err != nil {
+	if err = withAccountLock(a.Address(), func() (err error) {
+		prog := dialog.NewProgressInfinite(al.wi.Label, "Generating block...", win)
+		prog.Show()
+		if paymentURL == "" {
+			hash, err = a.Send(account, n.Raw)
+			prog.Hide()
 			return
 		}
-	} else {
 		block, err := a.SendBlock(account, n.Raw)
 		prog.Hide()
 		if err != nil {
-			return err
+			return
 		}
 		if hash, err = block.Hash(); err != nil {
-			return err
+			return
 		}
 		prog = dialog.NewProgressInfinite(al.wi.Label, "Waiting for confirmation...", win)
 		prog.Show()
 		err = sendToPaymentURL(paymentURL, block)
 		prog.Hide()
-		if err != nil {
-			return err
+		return
+	}); err != nil {
+		return
+	}
+	if memo != "" {
+		if err := saveMemo(hash, memo); err != nil {
+			connLog.add("Memo", err)
 		}
 	}
-	showSuccessDialog(win, hash)
+	trackConfirmation(hash)
+	if addressBook.lookup(account) == nil {
+		dialog.ShowConfirm("Address Book", "Save recipient to the address book?", func(ok bool) {
+			if ok {
+				showContactDialog(win, nil, contact{Address: account, Memo: memo}, nil)
+			}
+		}, win)
+	}
 	return
 }
 
@@ -340,45 +587,12 @@ func sendToPaymentURL(paymentURL string, block *rpc.Block) (err error) {
 	return
 }
 
-func showSuccessDialog(win fyne.Window, hash rpc.BlockHash) {
-	var (
-		url, _    = url.Parse("https://nanolooker.com/block/" + hash.String())
-		label     = widget.NewLabel("Sent with block hash")
-		hyperlink = widget.NewHyperlink(hash.String(), url)
-	)
-	dialog.ShowCustom("Success", "OK", container.NewHBox(label, hyperlink), win)
-}
-
 func (al *accountList) receive(win fyne.Window) (err error) {
-	a, err := al.wi.w.NewAccount(&al.selectedAccount.Index)
-	if err != nil {
-		return
-	}
-	if a.Address() != al.selectedAccount.address {
-		return errors.New("Address mismatch")
-	}
-	prog := dialog.NewProgressInfinite(al.wi.Label, "Receiving pending amounts...", win)
-	prog.Show()
-	err = a.ReceivePendings()
-	prog.Hide()
-	return
+	return al.receivePendingsOf(win, []*accountInfo{al.selectedAccount})
 }
 
 func (al *accountList) receiveAll(win fyne.Window) (err error) {
-	for _, ai := range al.wi.Accounts {
-		a, err := al.wi.w.NewAccount(&ai.Index)
-		if err != nil {
-			return err
-		}
-		if a.Address() != ai.address {
-			return errors.New("Address mismatch")
-		}
-	}
-	prog := dialog.NewProgressInfinite(al.wi.Label, "Receiving pending amounts...", win)
-	prog.Show()
-	err = al.wi.w.ReceivePendings()
-	prog.Hide()
-	return
+	return al.receivePendingsOf(win, al.wi.accountsList)
 }
 
 func (al *accountList) showChangeRepDialog(win fyne.Window) {
@@ -387,21 +601,34 @@ func (al *accountList) showChangeRepDialog(win fyne.Window) {
 		currentRep, _ = rpcClient.AccountRepresentative(al.selectedAccount.address)
 		label         = newCopyableLabel(win, currentRep)
 		account       = widget.NewEntry()
-		scroll        = container.NewHScroll(account)
-		content       = widget.NewForm(
+		contacts      = widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+			showContactPicker(win, func(c *contact) { account.SetText(c.Address) })
+		})
+		errLabel, isValid = newAddressError()
+		scroll            = container.NewHScroll(account)
+		content           = widget.NewForm(
 			widget.NewFormItem("Current representative", label),
-			widget.NewFormItem("New representative", scroll),
+			widget.NewFormItem("New representative", container.NewVBox(
+				container.NewBorder(nil, nil, nil, contacts, scroll), errLabel,
+			)),
 		)
 	)
 	scroll.SetMinSize(fyne.NewSize(580, 0))
 	account.SetPlaceHolder("Representative address")
-	dialog.ShowCustomConfirm("Change representative", "OK", "Cancel", content, func(ok bool) {
+	d := newConfirmDialog("Change representative", "OK", "Cancel", content, func(ok bool) {
 		if ok {
-			if err := al.changeRep(win, account.Text); err != nil {
+			rep, err := validateAddress(account.Text)
+			if err == nil {
+				err = al.changeRep(win, rep)
+			}
+			if err != nil {
 				dialog.ShowError(err, win)
 			}
 		}
 	}, win)
+	account.OnChanged = func(s string) { d.setValid(isValid(s)) }
+	d.setValid(false)
+	d.Show()
 }
 
 func (al *accountList) changeRep(win fyne.Window, account string) (err error) {
@@ -412,13 +639,17 @@ func (al *accountList) changeRep(win fyne.Window, account string) (err error) {
 	if a.Address() != al.selectedAccount.address {
 		return errors.New("Address mismatch")
 	}
+	var hash rpc.BlockHash
 	prog := dialog.NewProgressInfinite(al.wi.Label, "Generating block...", win)
 	prog.Show()
-	hash, err := a.ChangeRep(account)
+	err = withAccountLock(a.Address(), func() (err error) {
+		hash, err = a.ChangeRep(account)
+		return
+	})
 	prog.Hide()
 	if err != nil {
 		return
 	}
-	showSuccessDialog(win, hash)
+	trackConfirmation(hash)
 	return
 }
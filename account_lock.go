@@ -0,0 +1,24 @@
+package main
+
+import "sync"
+
+var accountLocks = struct {
+	m     sync.Mutex
+	locks map[string]*sync.Mutex
+}{locks: make(map[string]*sync.Mutex)}
+
+// withAccountLock builds and publishes blocks on account while holding a
+// lock shared by every path that does so, so that two blocks are never
+// built on the same frontier.
+func withAccountLock(account string, cb func() error) error {
+	accountLocks.m.Lock()
+	m, ok := accountLocks.locks[account]
+	if !ok {
+		m = &sync.Mutex{}
+		accountLocks.locks[account] = m
+	}
+	accountLocks.m.Unlock()
+	m.Lock()
+	defer m.Unlock()
+	return cb()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/util"
+)
+
+const addressAlphabet = "13456789abcdefghijkmnopqrstuwxyz"
+
+// validateAddress checks the prefix, alphabet and checksum of address, and
+// returns it normalised to the nano_ prefix.
+func validateAddress(address string) (normalized string, err error) {
+	address = strings.TrimSpace(address)
+	var body string
+	switch {
+	case address == "":
+		return "", errors.New("Address is empty")
+	case strings.HasPrefix(address, "nano_"):
+		body = address[len("nano_"):]
+	case strings.HasPrefix(address, "xrb_"):
+		body = address[len("xrb_"):]
+	default:
+		return "", errors.New("Address must start with nano_ or xrb_")
+	}
+	for _, c := range body {
+		if !strings.ContainsRune(addressAlphabet, c) {
+			return "", fmt.Errorf("Invalid character %q in address", c)
+		}
+	}
+	if len(body) != 60 {
+		return "", fmt.Errorf("Address has %d characters after the prefix, expected 60", len(body))
+	}
+	if body[0] != '1' && body[0] != '3' {
+		return "", errors.New("Invalid address")
+	}
+	pubkey, err := util.AddressToPubkey("nano_" + body)
+	if err != nil {
+		return "", errors.New("Address checksum mismatch")
+	}
+	return util.PubkeyToAddress(pubkey)
+}
+
+// newAddressError returns a label for showing address validation errors
+// inline, and a function that validates s and updates the label. Empty
+// input is reported as invalid without an error message.
+func newAddressError() (label *widget.Label, validate func(s string) bool) {
+	label = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	label.Hide()
+	validate = func(s string) bool {
+		if strings.TrimSpace(s) == "" {
+			label.Hide()
+			return false
+		}
+		if _, err := validateAddress(s); err != nil {
+			label.SetText(err.Error())
+			label.Show()
+			return false
+		}
+		label.Hide()
+		return true
+	}
+	return
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/util"
+	"github.com/spf13/viper"
+)
+
+var addressBook = &addressBookType{}
+
+type addressBookType struct {
+	m        sync.Mutex
+	contacts []*contact
+}
+
+type contact struct {
+	Name, Address string
+	Amount, Memo  string
+}
+
+func (ab *addressBookType) load() (err error) {
+	var contacts []*contact
+	if err = viper.UnmarshalKey("contacts", &contacts); err != nil {
+		return
+	}
+	ab.m.Lock()
+	ab.contacts = contacts
+	ab.sort()
+	ab.m.Unlock()
+	return
+}
+
+func (ab *addressBookType) save() (err error) {
+	ab.m.Lock()
+	viper.Set("contacts", ab.contacts)
+	ab.m.Unlock()
+	return viper.WriteConfig()
+}
+
+func (ab *addressBookType) sort() {
+	sort.Slice(ab.contacts, func(i, j int) bool {
+		return strings.ToLower(ab.contacts[i].Name) < strings.ToLower(ab.contacts[j].Name)
+	})
+}
+
+func (ab *addressBookType) getContacts(filter string) (contacts []*contact) {
+	filter = strings.ToLower(filter)
+	ab.m.Lock()
+	for _, c := range ab.contacts {
+		if strings.Contains(strings.ToLower(c.Name), filter) || strings.Contains(c.Address, filter) {
+			contacts = append(contacts, c)
+		}
+	}
+	ab.m.Unlock()
+	return
+}
+
+func (ab *addressBookType) lookup(address string) (c *contact) {
+	ab.m.Lock()
+	for _, c2 := range ab.contacts {
+		if c2.Address == address {
+			c = c2
+			break
+		}
+	}
+	ab.m.Unlock()
+	return
+}
+
+func (ab *addressBookType) put(c *contact, c2 contact) (err error) {
+	if c2.Name == "" {
+		return errors.New("Name is empty")
+	}
+	pubkey, err := util.AddressToPubkey(c2.Address)
+	if err != nil {
+		return
+	}
+	if c2.Address, err = util.PubkeyToAddress(pubkey); err != nil {
+		return
+	}
+	if c2.Amount != "" {
+		if _, err = util.NanoAmountFromString(c2.Amount); err != nil {
+			return
+		}
+	}
+	ab.m.Lock()
+	if c == nil {
+		c = &contact{}
+		ab.contacts = append(ab.contacts, c)
+	}
+	*c = c2
+	ab.sort()
+	ab.m.Unlock()
+	return ab.save()
+}
+
+func (ab *addressBookType) remove(c *contact) (err error) {
+	ab.m.Lock()
+	for i := range ab.contacts {
+		if ab.contacts[i] == c {
+			ab.contacts = append(ab.contacts[:i], ab.contacts[i+1:]...)
+			break
+		}
+	}
+	ab.m.Unlock()
+	return ab.save()
+}
+
+type addressBookList struct {
+	list                    *widget.List
+	addButton, removeButton *widget.Button
+	editButton              *widget.Button
+	contacts                []*contact
+	selectedID              widget.ListItemID
+}
+
+func newAddressBookList() (abl *addressBookList) {
+	win := fyne.CurrentApp().NewWindow("Address Book")
+	abl = &addressBookList{
+		list: widget.NewList(
+			func() int { return len(abl.contacts) },
+			func() fyne.CanvasObject {
+				return fyne.NewContainerWithLayout(
+					newHBoxLayout([]int{200, 600, 150}), newCopyableLabel(win, ""),
+					newCopyableLabel(win, ""), newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+				)
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				if id >= len(abl.contacts) {
+					return
+				}
+				c := abl.contacts[id]
+				getLabel := func(i int) *contextMenuLabel {
+					return item.(*fyne.Container).Objects[i].(*contextMenuLabel)
+				}
+				getLabel(0).SetText(c.Name)
+				getLabel(1).SetText(c.Address)
+				getLabel(2).SetText(c.Amount)
+				getLabel(3).SetText(c.Memo)
+				for i := 0; i < 4; i++ {
+					getLabel(i).tapped = func() { abl.list.Select(id) }
+				}
+			},
+		),
+		addButton: widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() {
+			showContactDialog(win, nil, contact{}, abl.refresh)
+		}),
+		editButton: widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), func() {
+			if c := abl.selectedContact(); c != nil {
+				showContactDialog(win, c, *c, abl.refresh)
+			}
+		}),
+		removeButton: widget.NewButtonWithIcon("Remove", theme.ContentRemoveIcon(), func() {
+			c := abl.selectedContact()
+			if c == nil {
+				return
+			}
+			if err := addressBook.remove(c); err != nil {
+				dialog.ShowError(err, win)
+			}
+			abl.refresh()
+		}),
+	}
+	abl.list.OnSelected = func(id widget.ListItemID) { abl.setContact(id) }
+	abl.list.OnUnselected = func(id widget.ListItemID) { abl.setContact(-1) }
+	abl.setContact(-1)
+	abl.refresh()
+	win.SetContent(container.NewBorder(
+		widget.NewLabel("Contacts:"),
+		widget.NewHBox(abl.addButton, abl.editButton, abl.removeButton),
+		nil, nil, abl.list,
+	))
+	win.Resize(fyne.NewSize(1200, 400))
+	win.CenterOnScreen()
+	win.Show()
+	return
+}
+
+func (abl *addressBookList) refresh() {
+	abl.contacts = addressBook.getContacts("")
+	if abl.selectedID >= 0 {
+		abl.list.Unselect(abl.selectedID)
+	}
+	abl.list.Refresh()
+}
+
+func (abl *addressBookList) selectedContact() *contact {
+	if abl.selectedID < 0 || abl.selectedID >= len(abl.contacts) {
+		return nil
+	}
+	return abl.contacts[abl.selectedID]
+}
+
+func (abl *addressBookList) setContact(id widget.ListItemID) {
+	abl.selectedID = id
+	if id < 0 {
+		abl.editButton.Disable()
+		abl.removeButton.Disable()
+	} else {
+		abl.editButton.Enable()
+		abl.removeButton.Enable()
+	}
+}
+
+func showContactDialog(win fyne.Window, c *contact, c2 contact, callback func()) {
+	var (
+		name              = widget.NewEntry()
+		address           = widget.NewEntry()
+		amount            = widget.NewEntry()
+		memo              = widget.NewEntry()
+		scroll            = container.NewHScroll(address)
+		errLabel, isValid = newAddressError()
+		content           = widget.NewForm(
+			widget.NewFormItem("Name", container.NewHScroll(name)),
+			widget.NewFormItem("Address", container.NewVBox(scroll, errLabel)),
+			widget.NewFormItem("Default amount", container.NewHScroll(amount)),
+			widget.NewFormItem("Memo", container.NewHScroll(memo)),
+		)
+		title = "New Contact"
+	)
+	if c != nil {
+		title = "Edit Contact"
+	}
+	name.SetText(c2.Name)
+	address.SetText(c2.Address)
+	amount.SetText(c2.Amount)
+	memo.SetText(c2.Memo)
+	scroll.SetMinSize(fyne.NewSize(580, 0))
+	amount.SetPlaceHolder("Amount of NANO (optional)")
+	memo.SetPlaceHolder("Optional")
+	d := newConfirmDialog(title, "OK", "Cancel", content, func(ok bool) {
+		if ok {
+			err := addressBook.put(c, contact{
+				Name:    name.Text,
+				Address: address.Text,
+				Amount:  amount.Text,
+				Memo:    memo.Text,
+			})
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if callback != nil {
+				callback()
+			}
+		}
+	}, win)
+	address.OnChanged = func(s string) { d.setValid(isValid(s)) }
+	d.setValid(isValid(address.Text))
+	d.Show()
+}
+
+func showContactPicker(win fyne.Window, callback func(*contact)) {
+	var (
+		d        dialog.Dialog
+		contacts = addressBook.getContacts("")
+		search   = widget.NewEntry()
+		list     = widget.NewList(
+			func() int { return len(contacts) },
+			func() fyne.CanvasObject {
+				return fyne.NewContainerWithLayout(
+					newHBoxLayout([]int{200}), widget.NewLabel(""), widget.NewLabel(""),
+				)
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				if id >= len(contacts) {
+					return
+				}
+				objects := item.(*fyne.Container).Objects
+				objects[0].(*widget.Label).SetText(contacts[id].Name)
+				objects[1].(*widget.Label).SetText(contacts[id].Address)
+			},
+		)
+		scroll = container.NewHScroll(search)
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		list.Unselect(id)
+		d.Hide()
+		callback(contacts[id])
+	}
+	search.SetPlaceHolder("Search by name or address")
+	search.OnChanged = func(s string) {
+		contacts = addressBook.getContacts(s)
+		list.Refresh()
+	}
+	scroll.SetMinSize(fyne.NewSize(800, 0))
+	content := container.NewBorder(scroll, nil, nil, nil, list)
+	d = dialog.NewCustom("Address Book", "Cancel", content, win)
+	d.Show()
+	d.Resize(fyne.NewSize(850, 400))
+}
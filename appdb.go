@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"sync"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+var appDBMutex sync.Mutex
+
+var appDBSchema = []string{
+	`CREATE TABLE IF NOT EXISTS account_cache (
+		address TEXT PRIMARY KEY,
+		balance TEXT NOT NULL,
+		pending TEXT NOT NULL,
+		frontier TEXT NOT NULL DEFAULT '',
+		updated INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS memos (
+		hash TEXT PRIMARY KEY,
+		memo TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS batch_rows (
+		batch TEXT NOT NULL,
+		row INTEGER NOT NULL,
+		address TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		memo TEXT NOT NULL,
+		status TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		block TEXT NOT NULL,
+		error TEXT NOT NULL,
+		PRIMARY KEY (batch, row)
+	)`,
+	`CREATE TABLE IF NOT EXISTS outbound_blocks (
+		hash TEXT PRIMARY KEY,
+		subtype TEXT NOT NULL,
+		block TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		next_retry INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		created INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS block_confirmations (
+		hash TEXT PRIMARY KEY,
+		published INTEGER NOT NULL,
+		confirmed INTEGER NOT NULL
+	)`,
+}
+
+// withAppDB opens the app's local state database, which holds data that is
+// not configuration.
+func withAppDB(cb func(*sql.DB) error) (err error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return
+	}
+	appDBMutex.Lock()
+	defer appDBMutex.Unlock()
+	db, err := sql.Open("sqlite3", filepath.Join(home, "gonano-gui.db"))
+	if err != nil {
+		return
+	}
+	defer db.Close()
+	for _, stmt := range appDBSchema {
+		if _, err = db.Exec(stmt); err != nil {
+			return
+		}
+	}
+	return cb(db)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne"
+)
+
+// autoReceive pockets sends to accounts of unlocked wallets which have
+// auto-receive enabled, as they are reported by the websocket.
+func (wl *walletList) autoReceive(e interface{}) {
+	pe, ok := e.(pendingArrivedEvent)
+	if !ok {
+		return
+	}
+	wi := wl.findAccount(pe.account)
+	if wi == nil || !wi.AutoReceive || wi.w == nil || wi.IsWatchOnly || wi.IsLedger {
+		return
+	}
+	ai := wi.Accounts[pe.account]
+	if wi.isDust(ai, pe.amount.Raw) {
+		return
+	}
+	name := ai.address
+	if ai.Label != "" {
+		name = ai.Label
+	}
+	a, err := wi.w.NewAccount(&ai.Index)
+	if err == nil && a.Address() != ai.address {
+		err = errors.New("Address mismatch")
+	}
+	if err == nil {
+		err = withAccountLock(ai.address, func() (err error) {
+			_, err = a.ReceivePending(pe.hash)
+			return
+		})
+	}
+	if err != nil {
+		connLog.add("Auto-receive", err)
+		fyne.CurrentApp().SendNotification(fyne.NewNotification(
+			"Auto-receive failed", fmt.Sprintf("%s: %v", name, err),
+		))
+		return
+	}
+	fyne.CurrentApp().SendNotification(fyne.NewNotification(
+		"Received "+rawToNano(pe.amount.Raw)+" NANO",
+		fmt.Sprintf("%s (wallet %s)", name, wi.Label),
+	))
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/layout"
+	"fyne.io/fyne/storage"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/hectorchu/gonano/util"
+	"github.com/hectorchu/gonano/wallet"
+)
+
+const (
+	batchSigned = "signed"
+	batchSent   = "sent"
+	batchFailed = "failed"
+)
+
+type batchRow struct {
+	address, amount, memo string
+	raw                   *big.Int
+	invalid               error
+	status                string
+	hash                  rpc.BlockHash
+	err                   string
+}
+
+type batchSend struct {
+	al                     *accountList
+	wi                     *walletInfo
+	ai                     *accountInfo
+	list                   *widget.List
+	openButton, sendButton *widget.Button
+	saveButton             *widget.Button
+	summary                *widget.Label
+	progress               *widget.ProgressBar
+	batch                  string
+	rows                   []*batchRow
+}
+
+func newBatchSend(al *accountList, wi *walletInfo, ai *accountInfo) (bs *batchSend) {
+	win := fyne.CurrentApp().NewWindow("Batch Send from " + ai.address)
+	bs = &batchSend{
+		al: al,
+		wi: wi,
+		ai: ai,
+		list: widget.NewList(
+			func() int { return len(bs.rows) },
+			func() fyne.CanvasObject {
+				return fyne.NewContainerWithLayout(
+					newHBoxLayout([]int{40, 600, 200, 200}), widget.NewLabel(""), newCopyableLabel(win, ""),
+					newCopyableLabel(win, ""), newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+				)
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				if id >= len(bs.rows) {
+					return
+				}
+				r := bs.rows[id]
+				objects := item.(*fyne.Container).Objects
+				objects[0].(*widget.Label).SetText(fmt.Sprint(id + 1))
+				objects[1].(*contextMenuLabel).SetText(r.address)
+				objects[2].(*contextMenuLabel).SetText(r.amount)
+				objects[3].(*contextMenuLabel).SetText(r.memo)
+				objects[4].(*contextMenuLabel).SetText(r.statusText())
+			},
+		),
+		summary:  widget.NewLabel("Open a CSV file with rows of address,amount[,memo]"),
+		progress: widget.NewProgressBar(),
+	}
+	bs.openButton = widget.NewButtonWithIcon("Open CSV", theme.FolderOpenIcon(), func() {
+		d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err == nil && r != nil {
+				err = bs.load(r)
+				r.Close()
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		d.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".txt"}))
+		d.Show()
+	})
+	bs.sendButton = widget.NewButtonWithIcon("Send", theme.MailForwardIcon(), func() {
+		n, total := bs.remaining()
+		dialog.ShowConfirm("Batch Send",
+			fmt.Sprintf("Send %d payments totalling %s NANO?", n, rawToNano(total)),
+			func(ok bool) {
+				if ok {
+					go bs.run(win)
+				}
+			}, win)
+	})
+	bs.saveButton = widget.NewButtonWithIcon("Save Results", theme.DocumentSaveIcon(), func() {
+		dialog.ShowFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err == nil && w != nil {
+				err = bs.writeResults(w)
+				w.Close()
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+	})
+	bs.sendButton.Disable()
+	bs.saveButton.Disable()
+	win.SetContent(container.NewBorder(
+		bs.summary,
+		container.NewVBox(bs.progress, widget.NewHBox(
+			bs.openButton, bs.sendButton, layout.NewSpacer(), bs.saveButton,
+		)),
+		nil, nil, bs.list,
+	))
+	win.Resize(fyne.NewSize(1400, 600))
+	win.CenterOnScreen()
+	win.Show()
+	return
+}
+
+func (r *batchRow) statusText() string {
+	switch {
+	case r.invalid != nil:
+		return "Invalid: " + r.invalid.Error()
+	case r.status == batchSent:
+		return "Sent " + r.hash.String()
+	case r.status == batchSigned:
+		return "Signed " + r.hash.String()
+	case r.status == batchFailed:
+		return "Failed: " + r.err
+	}
+	return ""
+}
+
+// load parses a CSV file of payments. A batch is identified by the sending
+// account and the file contents, so that reopening the same file resumes it.
+func (bs *batchSend) load(r io.Reader) (err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	cr := csv.NewReader(strings.NewReader(string(data)))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return
+	}
+	if len(records) > 0 && len(records[0]) > 0 && strings.EqualFold(records[0][0], "address") {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return errors.New("No payments found")
+	}
+	sum := sha256.Sum256(append([]byte(bs.ai.address+"\n"), data...))
+	bs.batch = hex.EncodeToString(sum[:])
+	bs.rows = make([]*batchRow, len(records))
+	for i, rec := range records {
+		r := &batchRow{}
+		bs.rows[i] = r
+		if len(rec) < 2 || len(rec) > 3 {
+			r.invalid = errors.New("Expected address,amount[,memo]")
+			continue
+		}
+		r.address, r.amount = strings.TrimSpace(rec[0]), strings.TrimSpace(rec[1])
+		if len(rec) == 3 {
+			r.memo = strings.TrimSpace(rec[2])
+		}
+		if r.address, r.invalid = validateAddress(r.address); r.invalid != nil {
+			r.address = rec[0]
+			continue
+		}
+		n, err := util.NanoAmountFromString(r.amount)
+		if err != nil || n.Raw.Sign() <= 0 {
+			r.invalid = errors.New("Invalid amount")
+			continue
+		}
+		r.raw = n.Raw
+	}
+	if err = withAppDB(bs.loadJournal); err != nil {
+		return
+	}
+	bs.update()
+	return
+}
+
+func (bs *batchSend) loadJournal(db *sql.DB) (err error) {
+	rows, err := db.Query("SELECT row, status, hash, error FROM batch_rows WHERE batch = ?", bs.batch)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			i                 int
+			status, hash, msg string
+		)
+		if err = rows.Scan(&i, &status, &hash, &msg); err != nil {
+			return
+		}
+		if i < len(bs.rows) {
+			r := bs.rows[i]
+			r.status, r.err = status, msg
+			r.hash, _ = hex.DecodeString(hash)
+		}
+	}
+	return rows.Err()
+}
+
+func (bs *batchSend) journal(i int, r *batchRow, block *rpc.Block) error {
+	var blockJSON []byte
+	if block != nil {
+		var err error
+		if blockJSON, err = json.Marshal(block); err != nil {
+			return err
+		}
+	}
+	return withAppDB(func(db *sql.DB) (err error) {
+		_, err = db.Exec(`INSERT INTO batch_rows (batch, row, address, amount, memo, status, hash, block, error)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (batch, row) DO UPDATE SET
+			status = excluded.status, hash = excluded.hash, error = excluded.error,
+			block = CASE WHEN excluded.block = '' THEN block ELSE excluded.block END`,
+			bs.batch, i, r.address, r.raw.String(), r.memo, r.status, r.hash.String(), string(blockJSON), r.err,
+		)
+		return
+	})
+}
+
+// remaining returns the number and total amount of rows still to be sent.
+func (bs *batchSend) remaining() (n int, total *big.Int) {
+	total = new(big.Int)
+	for _, r := range bs.rows {
+		if r.invalid == nil && r.status != batchSent {
+			n++
+			total.Add(total, r.raw)
+		}
+	}
+	return
+}
+
+func (bs *batchSend) update() {
+	var invalid, sent int
+	for _, r := range bs.rows {
+		if r.invalid != nil {
+			invalid++
+		} else if r.status == batchSent {
+			sent++
+		}
+	}
+	n, total := bs.remaining()
+	bs.al.m.Lock()
+	balance := bs.ai.balance.Raw
+	bs.al.m.Unlock()
+	s := fmt.Sprintf("%d rows, %d to send, total %s NANO", len(bs.rows), n, rawToNano(total))
+	if balance != nil {
+		s += fmt.Sprintf(", balance %s NANO", rawToNano(balance))
+	}
+	if sent > 0 {
+		s += fmt.Sprintf(". %d rows already sent will be skipped", sent)
+	}
+	switch {
+	case invalid > 0:
+		s += fmt.Sprintf(". %d invalid rows must be fixed first", invalid)
+	case balance != nil && total.Cmp(balance) > 0:
+		s += ". Total exceeds the balance"
+	}
+	bs.summary.SetText(s)
+	if invalid == 0 && n > 0 && (balance == nil || total.Cmp(balance) <= 0) {
+		bs.sendButton.Enable()
+	} else {
+		bs.sendButton.Disable()
+	}
+	if sent > 0 {
+		bs.saveButton.Enable()
+	}
+	bs.list.Refresh()
+}
+
+// run sends the remaining rows one at a time. Each block is recorded in the
+// journal before it is published, so that a resumed run can tell whether it
+// reached the network.
+func (bs *batchSend) run(win fyne.Window) {
+	bs.openButton.Disable()
+	bs.sendButton.Disable()
+	defer func() {
+		bs.openButton.Enable()
+		bs.update()
+	}()
+	a, err := bs.wi.w.NewAccount(&bs.ai.Index)
+	if err != nil {
+		dialog.ShowError(err, win)
+		return
+	}
+	if a.Address() != bs.ai.address {
+		dialog.ShowError(errors.New("Address mismatch"), win)
+		return
+	}
+	bs.progress.Max = float64(len(bs.rows))
+	for i, r := range bs.rows {
+		bs.progress.SetValue(float64(i))
+		if r.invalid != nil || r.status == batchSent {
+			continue
+		}
+		err := withAccountLock(bs.ai.address, func() error { return bs.sendRow(a, i, r) })
+		bs.list.Refresh()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Row %d: %v", i+1, err), win)
+			return
+		}
+	}
+	bs.progress.SetValue(bs.progress.Max)
+}
+
+// sendRow settles a row with a journalled block, or signs, journals and
+// publishes a new block for it.
+func (bs *batchSend) sendRow(a *wallet.Account, i int, r *batchRow) (err error) {
+	if len(r.hash) > 0 {
+		done, err := bs.resume(i, r)
+		if err != nil {
+			bs.finish(i, r, err)
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	r.err = ""
+	block, err := a.SendBlock(r.address, r.raw)
+	if err == nil {
+		r.hash, err = block.Hash()
+	}
+	if err == nil {
+		r.status = batchSigned
+		err = bs.journal(i, r, block)
+	}
+	if err == nil {
+		if err = publishBlock(block, "send"); err != nil && blockExists(r.hash) {
+			err = nil
+		}
+	}
+	bs.finish(i, r, err)
+	return
+}
+
+// resume settles a row which already has a signed block in the journal,
+// republishing the block if the network does not have it. It returns done
+// if the row has been sent, and an error if the outcome is unknown. Only a
+// block rejected as a fork may be replaced by a newly signed one.
+func (bs *batchSend) resume(i int, r *batchRow) (done bool, err error) {
+	if blockExists(r.hash) {
+		bs.finish(i, r, nil)
+		return true, nil
+	}
+	var blockJSON string
+	if err = withAppDB(func(db *sql.DB) error {
+		return db.QueryRow("SELECT block FROM batch_rows WHERE batch = ? AND row = ?", bs.batch, i).Scan(&blockJSON)
+	}); err != nil {
+		return
+	}
+	var block rpc.Block
+	if err = json.Unmarshal([]byte(blockJSON), &block); err != nil {
+		return
+	}
+	if err = publishBlock(&block, "send"); err == nil || blockExists(r.hash) {
+		bs.finish(i, r, nil)
+		return true, nil
+	}
+	if isForkError(err) {
+		return false, nil
+	}
+	return
+}
+
+func (bs *batchSend) finish(i int, r *batchRow, err error) {
+	if err != nil {
+		r.status, r.err = batchFailed, err.Error()
+	} else {
+		r.status = batchSent
+		if r.memo != "" {
+			if err := saveMemo(r.hash, r.memo); err != nil {
+				connLog.add("Memo", err)
+			}
+		}
+	}
+	bs.journal(i, r, nil)
+}
+
+func (bs *batchSend) writeResults(w io.Writer) (err error) {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"address", "amount", "memo", "status", "hash", "error"})
+	for _, r := range bs.rows {
+		var hash, msg string
+		if r.hash != nil {
+			hash = r.hash.String()
+		}
+		if r.invalid != nil {
+			msg = r.invalid.Error()
+		} else {
+			msg = r.err
+		}
+		cw.Write([]string{r.address, r.amount, r.memo, r.status, hash, msg})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/layout"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+)
+
+// confirmDialog is a confirm dialog whose confirm button can be disabled
+// while the content is invalid.
+type confirmDialog struct {
+	popup                  *widget.PopUp
+	confirmButton, dismiss *widget.Button
+}
+
+func newConfirmDialog(
+	title, confirm, dismiss string, content fyne.CanvasObject, callback func(bool), win fyne.Window,
+) (d *confirmDialog) {
+	d = &confirmDialog{}
+	d.confirmButton = widget.NewButtonWithIcon(confirm, theme.ConfirmIcon(), func() {
+		d.popup.Hide()
+		callback(true)
+	})
+	d.confirmButton.Importance = widget.HighImportance
+	d.dismiss = widget.NewButtonWithIcon(dismiss, theme.CancelIcon(), func() {
+		d.popup.Hide()
+		callback(false)
+	})
+	d.popup = widget.NewModalPopUp(container.NewVBox(
+		widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		content,
+		container.NewHBox(layout.NewSpacer(), d.dismiss, d.confirmButton, layout.NewSpacer()),
+	), win.Canvas())
+	return
+}
+
+func (d *confirmDialog) Show() {
+	d.popup.Show()
+}
+
+func (d *confirmDialog) setValid(valid bool) {
+	if valid {
+		d.confirmButton.Enable()
+	} else {
+		d.confirmButton.Disable()
+	}
+}
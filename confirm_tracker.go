@@ -0,0 +1,143 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/layout"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+)
+
+const (
+	confirmPollInterval = 5 * time.Second
+	confirmTimeout      = 2 * time.Minute
+	toastHideDelay      = 10 * time.Second
+)
+
+// toasts holds non-modal notifications shown above the status bar.
+var toasts = container.NewVBox()
+
+// trackConfirmation shows a toast for a published block and follows it
+// until it is confirmed, either from the websocket or by polling, or until
+// it fails or times out.
+func trackConfirmation(hash rpc.BlockHash) {
+	var (
+		url, _    = url.Parse("https://nanolooker.com/block/" + hash.String())
+		status    = widget.NewLabel("Published")
+		hyperlink = widget.NewHyperlink(hash.String(), url)
+		toast     *fyne.Container
+		done      = make(chan bool)
+		confirmed = make(chan bool, 1)
+		published = time.Now()
+	)
+	closeButton := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		toasts.Remove(toast)
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+	})
+	toast = container.NewHBox(status, hyperlink, layout.NewSpacer(), closeButton)
+	toasts.Add(toast)
+	key := bus.subscribe(func(e interface{}) {
+		if e, ok := e.(blockConfirmedEvent); ok && e.hash.String() == hash.String() {
+			select {
+			case confirmed <- true:
+			default:
+			}
+		}
+	})
+	go func() {
+		defer bus.unsubscribe(key)
+		ticker := time.NewTicker(confirmPollInterval)
+		defer ticker.Stop()
+		timeout := time.After(confirmTimeout)
+		for {
+			select {
+			case <-done:
+				return
+			case <-confirmed:
+			case <-ticker.C:
+				if ok, err := pollConfirmation(hash); err != nil {
+					status.SetText("Failed: " + err.Error())
+					return
+				} else if !ok {
+					continue
+				}
+			case <-timeout:
+				status.SetText(fmt.Sprintf("Not confirmed after %v, see Pending outbound", confirmTimeout))
+				return
+			}
+			now := time.Now()
+			if err := saveConfirmation(hash, published, now); err != nil {
+				connLog.add("Confirmation", err)
+			}
+			status.SetText(fmt.Sprintf("Confirmed in %.1fs", now.Sub(published).Seconds()))
+			select {
+			case <-time.After(toastHideDelay):
+				closeButton.OnTapped()
+			case <-done:
+			}
+			return
+		}
+	}()
+}
+
+// pollConfirmation checks a block's confirmation status with the node. A
+// block whose outbound journal entry has failed is reported as an error.
+func pollConfirmation(hash rpc.BlockHash) (confirmed bool, err error) {
+	rpcClient := rpc.Client{URL: rpcURL}
+	if bi, err := rpcClient.BlockInfo(hash); err == nil && bi.Confirmed {
+		return true, nil
+	}
+	if ob := outbound.get(hash); ob != nil && ob.status == outboundFailed {
+		return false, fmt.Errorf("%s", ob.err)
+	}
+	return
+}
+
+func saveConfirmation(hash rpc.BlockHash, published, confirmed time.Time) error {
+	return withAppDB(func(db *sql.DB) (err error) {
+		_, err = db.Exec(`INSERT OR REPLACE INTO block_confirmations (hash, published, confirmed)
+			VALUES (?, ?, ?)`, hash.String(), published.UnixNano(), confirmed.UnixNano())
+		return
+	})
+}
+
+type blockConfirmation struct {
+	published, confirmed time.Time
+}
+
+// loadConfirmations returns the recorded confirmation times of those of the
+// given blocks which were tracked after publishing.
+func loadConfirmations(hashes []rpc.BlockHash) (confirmations map[string]blockConfirmation, err error) {
+	confirmations = make(map[string]blockConfirmation)
+	err = withAppDB(func(db *sql.DB) (err error) {
+		stmt, err := db.Prepare("SELECT published, confirmed FROM block_confirmations WHERE hash = ?")
+		if err != nil {
+			return
+		}
+		defer stmt.Close()
+		for _, hash := range hashes {
+			var published, confirmed int64
+			if err = stmt.QueryRow(hash.String()).Scan(&published, &confirmed); err == sql.ErrNoRows {
+				continue
+			} else if err != nil {
+				return
+			}
+			confirmations[hash.String()] = blockConfirmation{
+				published: time.Unix(0, published),
+				confirmed: time.Unix(0, confirmed),
+			}
+		}
+		return nil
+	})
+	return
+}
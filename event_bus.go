@@ -0,0 +1,124 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/hectorchu/gonano/util"
+)
+
+type blockConfirmedEvent struct {
+	block   *rpc.Block
+	subtype string
+	hash    rpc.BlockHash
+}
+
+type balanceChangedEvent struct {
+	account          string
+	balance, pending util.NanoAmount
+}
+
+type pendingArrivedEvent struct {
+	account, source string
+	hash            rpc.BlockHash
+	amount          util.NanoAmount
+}
+
+type chainUpdatedEvent struct {
+	address string
+}
+
+var bus = &eventBus{subs: make(map[int]*eventSub)}
+
+// eventBus delivers events to each subscriber on its own goroutine, so that
+// a slow subscriber only delays its own queue. Publishing never blocks:
+// queues are unbounded, and a queued balance change is replaced by a newer
+// one for the same account.
+type eventBus struct {
+	m    sync.Mutex
+	key  int
+	subs map[int]*eventSub
+}
+
+type eventSub struct {
+	m      sync.Mutex
+	queue  []interface{}
+	signal chan bool
+	quit   chan bool
+}
+
+func (b *eventBus) subscribe(f func(interface{})) (key int) {
+	sub := &eventSub{
+		signal: make(chan bool, 1),
+		quit:   make(chan bool),
+	}
+	b.m.Lock()
+	key = b.key
+	b.key++
+	b.subs[key] = sub
+	b.m.Unlock()
+	go func() {
+		for {
+			select {
+			case <-sub.signal:
+			case <-sub.quit:
+				return
+			}
+			for _, e := range sub.take() {
+				select {
+				case <-sub.quit:
+					return
+				default:
+				}
+				f(e)
+			}
+		}
+	}()
+	return
+}
+
+func (b *eventBus) unsubscribe(key int) {
+	b.m.Lock()
+	if sub, ok := b.subs[key]; ok {
+		delete(b.subs, key)
+		close(sub.quit)
+	}
+	b.m.Unlock()
+}
+
+func (b *eventBus) publish(e interface{}) {
+	b.m.Lock()
+	subs := make([]*eventSub, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.m.Unlock()
+	for _, sub := range subs {
+		sub.put(e)
+	}
+}
+
+func (sub *eventSub) put(e interface{}) {
+	sub.m.Lock()
+	defer sub.m.Unlock()
+	if e, ok := e.(balanceChangedEvent); ok {
+		for i, q := range sub.queue {
+			if q, ok := q.(balanceChangedEvent); ok && q.account == e.account {
+				sub.queue[i] = e
+				return
+			}
+		}
+	}
+	sub.queue = append(sub.queue, e)
+	select {
+	case sub.signal <- true:
+	default:
+	}
+}
+
+func (sub *eventSub) take() (queue []interface{}) {
+	sub.m.Lock()
+	queue, sub.queue = sub.queue, nil
+	sub.m.Unlock()
+	return
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/layout"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/hectorchu/gonano/util"
+)
+
+const historyPageSize = 25
+
+type historyList struct {
+	ai                     *accountInfo
+	list                   *widget.List
+	prevButton, nextButton *widget.Button
+	pageLabel              *widget.Label
+	history                []rpc.AccountHistoryRaw
+	confirmations          map[string]blockConfirmation
+	heads                  []rpc.BlockHash
+	previous               rpc.BlockHash
+}
+
+func newHistoryList(ai *accountInfo) (hl *historyList) {
+	win := fyne.CurrentApp().NewWindow("History for " + ai.address)
+	hl = &historyList{
+		ai: ai,
+		list: widget.NewList(
+			func() int { return len(hl.history) },
+			func() fyne.CanvasObject {
+				return fyne.NewContainerWithLayout(
+					newHBoxLayout([]int{80, 600, 200, 160, 240}), newCopyableLabel(win, ""),
+					newCopyableLabel(win, ""), newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+					newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+				)
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				if id >= len(hl.history) {
+					return
+				}
+				h := hl.history[id]
+				getLabel := func(i int) *contextMenuLabel {
+					return item.(*fyne.Container).Objects[i].(*contextMenuLabel)
+				}
+				typ, account, amount := historyEntryFields(h)
+				getLabel(0).SetText(typ)
+				getLabel(1).SetText(account)
+				getLabel(2).SetText(amount)
+				timestamp := ""
+				if h.LocalTimestamp != 0 {
+					timestamp = time.Unix(int64(h.LocalTimestamp), 0).Local().Format("2006-01-02 15:04:05")
+				}
+				getLabel(3).SetText(timestamp)
+				confirmed := ""
+				if c, ok := hl.confirmations[h.Hash.String()]; ok {
+					confirmed = fmt.Sprintf("Confirmed %s (%.1fs)",
+						c.confirmed.Local().Format("15:04:05"), c.confirmed.Sub(c.published).Seconds())
+				}
+				getLabel(4).SetText(confirmed)
+				getLabel(5).SetText(h.Hash.String())
+				for i := 0; i < 6; i++ {
+					getLabel(i).tapped = func() { hl.list.Select(id) }
+				}
+			},
+		),
+		prevButton: widget.NewButtonWithIcon("Newer", theme.NavigateBackIcon(), func() {
+			if len(hl.heads) > 1 {
+				hl.heads = hl.heads[:len(hl.heads)-1]
+				hl.load(win)
+			}
+		}),
+		nextButton: widget.NewButtonWithIcon("Older", theme.NavigateNextIcon(), func() {
+			if hl.previous != nil {
+				hl.heads = append(hl.heads, hl.previous)
+				hl.load(win)
+			}
+		}),
+		pageLabel: widget.NewLabel(""),
+		heads:     []rpc.BlockHash{nil},
+	}
+	hl.list.OnSelected = func(id widget.ListItemID) {
+		hl.list.Unselect(id)
+		if err := hl.showBlockDialog(win, hl.history[id].Hash); err != nil {
+			dialog.ShowError(err, win)
+		}
+	}
+	win.SetContent(container.NewBorder(
+		widget.NewLabel("History:"),
+		widget.NewHBox(hl.prevButton, hl.nextButton, layout.NewSpacer(), hl.pageLabel),
+		nil, nil, hl.list,
+	))
+	win.Resize(fyne.NewSize(1600, 600))
+	win.CenterOnScreen()
+	win.Show()
+	hl.load(win)
+	return
+}
+
+func historyEntryFields(h rpc.AccountHistoryRaw) (typ, account, amount string) {
+	typ, account = h.Type, h.Account
+	if typ == "state" {
+		typ = h.Subtype
+	}
+	if typ == "change" {
+		account = h.Representative
+	}
+	if h.Amount != nil && typ != "change" {
+		amount = util.NanoAmount{Raw: &h.Amount.Int}.String()
+	}
+	return
+}
+
+func (hl *historyList) load(win fyne.Window) {
+	rpcClient := rpc.Client{URL: rpcURL}
+	prog := dialog.NewProgressInfinite("History", "Loading history...", win)
+	prog.Show()
+	history, previous, err := rpcClient.AccountHistoryRaw(hl.ai.address, historyPageSize, hl.heads[len(hl.heads)-1])
+	prog.Hide()
+	if err != nil {
+		dialog.ShowError(err, win)
+		history, previous = nil, nil
+	}
+	hl.history, hl.previous = history, previous
+	hashes := make([]rpc.BlockHash, len(history))
+	for i, h := range history {
+		hashes[i] = h.Hash
+	}
+	hl.confirmations, _ = loadConfirmations(hashes)
+	if len(hl.heads) > 1 {
+		hl.prevButton.Enable()
+	} else {
+		hl.prevButton.Disable()
+	}
+	if hl.previous != nil {
+		hl.nextButton.Enable()
+	} else {
+		hl.nextButton.Disable()
+	}
+	hl.pageLabel.SetText(fmt.Sprintf("Page %d", len(hl.heads)))
+	hl.list.Refresh()
+}
+
+func (hl *historyList) showBlockDialog(win fyne.Window, hash rpc.BlockHash) (err error) {
+	rpcClient := rpc.Client{URL: rpcURL}
+	info, err := rpcClient.BlockInfo(hash)
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return
+	}
+	var (
+		entry   = widget.NewMultiLineEntry()
+		scroll  = container.NewScroll(entry)
+		content = fyne.CanvasObject(scroll)
+	)
+	entry.SetText(string(data))
+	scroll.SetMinSize(fyne.NewSize(800, 500))
+	if memo := loadMemo(hash); memo != "" {
+		content = container.NewBorder(widget.NewLabel("Memo: "+memo), nil, nil, nil, scroll)
+	}
+	dialog.ShowCustom("Block "+hash.String(), "OK", content, win)
+	return
+}
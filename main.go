@@ -1,14 +1,13 @@
 package main
 
 import (
-	"math"
+	"os"
 
 	"fyne.io/fyne"
 	"fyne.io/fyne/app"
 	"fyne.io/fyne/container"
 	"fyne.io/fyne/dialog"
 	"fyne.io/fyne/theme"
-	"github.com/hectorchu/gonano/rpc"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
@@ -20,20 +19,30 @@ func main() {
 	if err := initConfig(); err != nil {
 		dialog.ShowError(err, win)
 	}
+	if err := addressBook.load(); err != nil {
+		dialog.ShowError(err, win)
+	}
 	go loadTokens(win)
 	al := newAccountList(win)
 	wl := newWalletList(win, al)
 	al.wl = wl
 	split := container.NewHSplit(wl.widget, al.widget)
 	split.SetOffset(0)
-	win.SetContent(split)
+	sb := newStatusBar(win)
+	win.SetContent(container.NewBorder(nil, container.NewVBox(toasts, sb.widget), nil, nil, split))
+	go chooseRPC()
+	go nodes.monitor()
+	go wsClient.run()
+	go outbound.run()
+	if len(os.Args) > 1 && isPaymentURI(os.Args[1]) {
+		al.openPaymentURI(win, os.Args[1])
+	}
 	win.Resize(fyne.NewSize(1000, 600))
 	win.CenterOnScreen()
 	win.ShowAndRun()
 }
 
 var lightTheme bool
-var rpcURL string
 
 func initConfig() (err error) {
 	home, err := homedir.Dir()
@@ -48,7 +57,7 @@ func initConfig() (err error) {
 	}
 	lightTheme = viper.GetBool("lightTheme")
 	setTheme()
-	chooseRPC()
+	nodes.load()
 	return
 }
 
@@ -67,23 +76,6 @@ func toggleTheme() {
 	viper.WriteConfig()
 }
 
-func chooseRPC() {
-	var n uint64 = math.MaxUint64
-	for _, url := range []string{
-		"https://gonano.dev/rpc",
-		"https://mynano.ninja/api/node",
-		"https://proxy.nanos.cc/proxy",
-		"https://proxy.powernode.cc/proxy",
-		"https://rainstorm.city/api",
-	} {
-		rpcClient := rpc.Client{URL: url}
-		if _, _, unchecked, err := rpcClient.BlockCount(); err == nil && unchecked < n {
-			rpcURL = url
-			n = unchecked
-		}
-	}
-}
-
 func loadTokens(win fyne.Window) {
 	prog := dialog.NewProgressInfinite("Gonano", "Loading tokens...", win)
 	prog.Show()
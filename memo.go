@@ -0,0 +1,23 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/hectorchu/gonano/rpc"
+)
+
+// saveMemo records the memo given to a sent block.
+func saveMemo(hash rpc.BlockHash, memo string) error {
+	return withAppDB(func(db *sql.DB) (err error) {
+		_, err = db.Exec("INSERT OR REPLACE INTO memos (hash, memo) VALUES (?, ?)", hash.String(), memo)
+		return
+	})
+}
+
+// loadMemo returns the memo of a sent block, or "" if it has none.
+func loadMemo(hash rpc.BlockHash) (memo string) {
+	withAppDB(func(db *sql.DB) error {
+		return db.QueryRow("SELECT memo FROM memos WHERE hash = ?", hash.String()).Scan(&memo)
+	})
+	return
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/spf13/viper"
+)
+
+// rpcURL is routed through the node list, which forwards each request to
+// the current node and fails over to the next one on transport errors.
+const rpcURL = "gonano://rpc"
+
+const (
+	nodeDownTime         = time.Minute
+	probeTimeout         = 5 * time.Second
+	rpcAttemptTimeout    = 30 * time.Second
+	offlineRetryInterval = 30 * time.Second
+)
+
+var defaultNodes = []string{
+	"https://gonano.dev/rpc",
+	"https://mynano.ninja/api/node",
+	"https://proxy.nanos.cc/proxy",
+	"https://proxy.powernode.cc/proxy",
+	"https://rainstorm.city/api",
+}
+
+var nodes = &nodeList{
+	down:  make(map[string]time.Time),
+	ready: make(chan bool),
+}
+
+type nodeList struct {
+	m         sync.Mutex
+	urls      []string
+	cur       string
+	down      map[string]time.Time
+	ready     chan bool
+	readyOnce sync.Once
+	offline   bool
+	online    []func()
+}
+
+func init() {
+	http.DefaultTransport.(*http.Transport).RegisterProtocol("gonano", nodes)
+}
+
+func (nl *nodeList) load() {
+	urls := viper.GetStringSlice("rpcNodes")
+	if len(urls) == 0 {
+		urls = defaultNodes
+	}
+	nl.m.Lock()
+	nl.urls = append([]string(nil), urls...)
+	nl.m.Unlock()
+}
+
+func (nl *nodeList) save() (err error) {
+	nl.m.Lock()
+	viper.Set("rpcNodes", nl.urls)
+	nl.m.Unlock()
+	return viper.WriteConfig()
+}
+
+func (nl *nodeList) getURLs() []string {
+	nl.m.Lock()
+	defer nl.m.Unlock()
+	return append([]string(nil), nl.urls...)
+}
+
+func (nl *nodeList) setURLs(urls []string) (err error) {
+	nl.m.Lock()
+	nl.urls = append([]string(nil), urls...)
+	nl.m.Unlock()
+	return nl.save()
+}
+
+func (nl *nodeList) current() string {
+	nl.m.Lock()
+	defer nl.m.Unlock()
+	return nl.cur
+}
+
+func (nl *nodeList) setCurrent(url string) {
+	nl.m.Lock()
+	nl.cur = url
+	delete(nl.down, url)
+	nl.m.Unlock()
+}
+
+func (nl *nodeList) setReady() {
+	nl.readyOnce.Do(func() { close(nl.ready) })
+}
+
+func (nl *nodeList) isReady() bool {
+	select {
+	case <-nl.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func (nl *nodeList) isOffline() bool {
+	nl.m.Lock()
+	defer nl.m.Unlock()
+	return nl.offline
+}
+
+// onOnline registers f to be called when a node becomes reachable again
+// after the app has been offline.
+func (nl *nodeList) onOnline(f func()) {
+	nl.m.Lock()
+	nl.online = append(nl.online, f)
+	nl.m.Unlock()
+}
+
+func (nl *nodeList) setOffline(offline bool) {
+	nl.m.Lock()
+	wasOffline := nl.offline
+	nl.offline = offline
+	online := append([]func(){}, nl.online...)
+	nl.m.Unlock()
+	if wasOffline && !offline {
+		for _, f := range online {
+			go f()
+		}
+	}
+}
+
+// monitor probes the nodes periodically while offline.
+func (nl *nodeList) monitor() {
+	for {
+		time.Sleep(offlineRetryInterval)
+		if nl.isOffline() {
+			chooseRPC()
+		}
+	}
+}
+
+func (nl *nodeList) setDown(url string) {
+	nl.m.Lock()
+	nl.down[url] = time.Now()
+	nl.m.Unlock()
+}
+
+// candidates returns the nodes to try in order: the current node first,
+// then the nodes following it in the list, with recently failed nodes last.
+func (nl *nodeList) candidates() (urls []string) {
+	nl.m.Lock()
+	defer nl.m.Unlock()
+	start := 0
+	for i, url := range nl.urls {
+		if url == nl.cur {
+			start = i
+			break
+		}
+	}
+	var down []string
+	for i := range nl.urls {
+		url := nl.urls[(start+i)%len(nl.urls)]
+		if t, ok := nl.down[url]; ok && time.Since(t) < nodeDownTime {
+			down = append(down, url)
+		} else {
+			urls = append(urls, url)
+		}
+	}
+	return append(urls, down...)
+}
+
+// RoundTrip implements http.RoundTripper for the gonano:// scheme. A node
+// which does not respond within rpcAttemptTimeout is failed over like one
+// which refuses the connection.
+func (nl *nodeList) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	var body []byte
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return
+		}
+	}
+	outbound.intercept(body)
+	select {
+	case <-nl.ready:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	if nl.isOffline() {
+		return nil, errors.New("Offline: no RPC node is reachable")
+	}
+	err = errors.New("No RPC nodes configured")
+	for _, node := range nl.candidates() {
+		var u *url.URL
+		if u, err = url.Parse(node); err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), rpcAttemptTimeout)
+		req2 := req.Clone(ctx)
+		req2.URL, req2.Host = u, ""
+		req2.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req2.ContentLength = int64(len(body))
+		if resp, err = http.DefaultTransport.RoundTrip(req2); err == nil {
+			if resp.StatusCode < 500 {
+				if node != nl.current() {
+					nl.setCurrent(node)
+				}
+				resp.Body = &cancelOnClose{resp.Body, cancel}
+				return
+			}
+			resp.Body.Close()
+			err = errors.New(resp.Status)
+		}
+		cancel()
+		connLog.add(node, err)
+		nl.setDown(node)
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+	}
+	nl.setOffline(true)
+	return nil, err
+}
+
+// cancelOnClose releases a forwarded request's timeout once its response
+// body has been read.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// chooseRPC probes all nodes concurrently. Requests are let through as soon
+// as the first node answers, and the node with the fewest unchecked blocks
+// is switched to once all probes have finished or timed out.
+func chooseRPC() {
+	type result struct {
+		i         int
+		unchecked uint64
+		err       error
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	urls := nodes.getURLs()
+	ch := make(chan result, len(urls))
+	for i, url := range urls {
+		go func(i int, url string) {
+			rpcClient := rpc.Client{URL: url, Ctx: ctx}
+			_, _, unchecked, err := rpcClient.BlockCount()
+			ch <- result{i, unchecked, err}
+		}(i, url)
+	}
+	var (
+		n    uint64 = math.MaxUint64
+		best        = -1
+	)
+	for range urls {
+		r := <-ch
+		if r.err != nil {
+			connLog.add(urls[r.i], r.err)
+			nodes.setDown(urls[r.i])
+			continue
+		}
+		if best < 0 {
+			nodes.setCurrent(urls[r.i])
+			nodes.setOffline(false)
+			nodes.setReady()
+		}
+		if r.unchecked < n || r.unchecked == n && r.i < best {
+			best, n = r.i, r.unchecked
+		}
+	}
+	if best >= 0 {
+		nodes.setCurrent(urls[best])
+	} else {
+		nodes.setOffline(true)
+	}
+	nodes.setReady()
+}
+
+type nodeListWindow struct {
+	list                 *widget.List
+	addButton            *contextMenuButton
+	removeButton         *widget.Button
+	upButton, downButton *widget.Button
+	urls                 []string
+	selectedID           widget.ListItemID
+}
+
+func newNodeListWindow() (nw *nodeListWindow) {
+	win := fyne.CurrentApp().NewWindow("RPC Nodes")
+	nw = &nodeListWindow{
+		list: widget.NewList(
+			func() int { return len(nw.urls) },
+			func() fyne.CanvasObject {
+				return fyne.NewContainerWithLayout(
+					newHBoxLayout([]int{500}), newCopyableLabel(win, ""), widget.NewLabel(""),
+				)
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				if id >= len(nw.urls) {
+					return
+				}
+				objects := item.(*fyne.Container).Objects
+				l := objects[0].(*contextMenuLabel)
+				l.SetText(nw.urls[id])
+				l.tapped = func() { nw.list.Select(id) }
+				var status string
+				if nw.urls[id] == nodes.current() {
+					status = "(current)"
+				}
+				objects[1].(*widget.Label).SetText(status)
+			},
+		),
+		addButton: newContextMenuButton("Add", theme.ContentAddIcon(), fyne.NewMenu("",
+			fyne.NewMenuItem("Local node", func() { nw.add(win, "http://[::1]:7076") }),
+			fyne.NewMenuItem("Custom endpoint", func() {
+				var (
+					entry   = widget.NewEntry()
+					scroll  = container.NewHScroll(entry)
+					content = widget.NewForm(widget.NewFormItem("URL", scroll))
+				)
+				scroll.SetMinSize(fyne.NewSize(400, 0))
+				entry.SetPlaceHolder("https://example.com/rpc")
+				dialog.ShowCustomConfirm("Add RPC node", "OK", "Cancel", content, func(ok bool) {
+					if ok {
+						nw.add(win, entry.Text)
+					}
+				}, win)
+			}),
+		)),
+		removeButton: widget.NewButtonWithIcon("Remove", theme.ContentRemoveIcon(), func() {
+			if nw.selectedID < 0 {
+				return
+			}
+			urls := append(nw.urls[:nw.selectedID:nw.selectedID], nw.urls[nw.selectedID+1:]...)
+			nw.list.Unselect(nw.selectedID)
+			nw.set(win, urls)
+		}),
+		upButton:   widget.NewButtonWithIcon("Up", theme.MoveUpIcon(), func() { nw.move(win, -1) }),
+		downButton: widget.NewButtonWithIcon("Down", theme.MoveDownIcon(), func() { nw.move(win, 1) }),
+		urls:       nodes.getURLs(),
+	}
+	nw.list.OnSelected = func(id widget.ListItemID) { nw.setNode(id) }
+	nw.list.OnUnselected = func(id widget.ListItemID) { nw.setNode(-1) }
+	nw.setNode(-1)
+	win.SetContent(container.NewBorder(
+		widget.NewLabel("RPC nodes (in failover order):"),
+		widget.NewHBox(nw.addButton, nw.removeButton, nw.upButton, nw.downButton),
+		nil, nil, nw.list,
+	))
+	win.Resize(fyne.NewSize(700, 400))
+	win.CenterOnScreen()
+	win.Show()
+	return
+}
+
+func (nw *nodeListWindow) setNode(id widget.ListItemID) {
+	nw.selectedID = id
+	if id < 0 {
+		nw.removeButton.Disable()
+		nw.upButton.Disable()
+		nw.downButton.Disable()
+	} else {
+		nw.removeButton.Enable()
+		nw.upButton.Enable()
+		nw.downButton.Enable()
+	}
+}
+
+func (nw *nodeListWindow) add(win fyne.Window, node string) {
+	if u, err := url.Parse(node); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		dialog.ShowError(errors.New("Invalid URL"), win)
+		return
+	}
+	for _, node2 := range nw.urls {
+		if node2 == node {
+			return
+		}
+	}
+	nw.set(win, append(nw.urls, node))
+}
+
+func (nw *nodeListWindow) move(win fyne.Window, delta int) {
+	i, j := nw.selectedID, nw.selectedID+delta
+	if i < 0 || j < 0 || j >= len(nw.urls) {
+		return
+	}
+	urls := append([]string(nil), nw.urls...)
+	urls[i], urls[j] = urls[j], urls[i]
+	nw.set(win, urls)
+	nw.list.Select(j)
+}
+
+func (nw *nodeListWindow) set(win fyne.Window, urls []string) {
+	nw.urls = urls
+	nw.list.Refresh()
+	if err := nodes.setURLs(urls); err != nil {
+		dialog.ShowError(err, win)
+	}
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/storage"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/ledger"
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/hectorchu/gonano/util"
+	"github.com/hectorchu/gonano/wallet/bip32"
+	"github.com/hectorchu/gonano/wallet/ed25519"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/blake2b"
+)
+
+// offlineRequest is the file exchanged between an online watch-only
+// instance and an offline signing instance. Block carries precomputed work,
+// and Previous is the contents of the block it follows, which lets the
+// signer show the amount and is cached by Ledger devices before signing.
+type offlineRequest struct {
+	Subtype  string     `json:"subtype"`
+	Block    *rpc.Block `json:"block"`
+	Previous *rpc.Block `json:"previous_block"`
+}
+
+// prepareOfflineRequest builds an unsigned send or change block for account
+// from its current frontier. target is the recipient or new representative.
+func prepareOfflineRequest(account, subtype, target string, amount *big.Int) (req *offlineRequest, err error) {
+	rpcClient := rpc.Client{URL: rpcURL}
+	info, err := rpcClient.AccountInfo(account)
+	if err != nil {
+		return
+	}
+	bi, err := rpcClient.BlockInfo(info.Frontier)
+	if err != nil {
+		return
+	}
+	block := &rpc.Block{
+		Type:           "state",
+		Account:        account,
+		Previous:       info.Frontier,
+		Representative: info.Representative,
+		Balance:        info.Balance,
+		Link:           make(rpc.BlockHash, 32),
+	}
+	switch subtype {
+	case "send":
+		if block.Link, err = util.AddressToPubkey(target); err != nil {
+			return
+		}
+		if block.Balance.Sub(&block.Balance.Int, amount).Sign() < 0 {
+			return nil, errors.New("Insufficient funds")
+		}
+	case "change":
+		block.Representative = target
+	default:
+		return nil, errors.New("Unsupported block type " + subtype)
+	}
+	workClient := rpc.Client{URL: workURL}
+	if block.Work, _, _, err = workClient.WorkGenerate(block.Previous, sendDifficulty); err != nil {
+		return
+	}
+	return &offlineRequest{Subtype: subtype, Block: block, Previous: bi.Contents}, nil
+}
+
+func readOfflineRequest(r io.Reader) (req *offlineRequest, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	req = &offlineRequest{}
+	if err = json.Unmarshal(data, req); err != nil {
+		return
+	}
+	if req.Block == nil || req.Previous == nil {
+		return nil, errors.New("Not an offline signing file")
+	}
+	if req.Subtype != "send" && req.Subtype != "change" {
+		return nil, errors.New("Unsupported block type " + req.Subtype)
+	}
+	if req.Block.Balance == nil || req.Previous.Account != req.Block.Account {
+		return nil, errors.New("Malformed offline signing file")
+	}
+	return
+}
+
+func (req *offlineRequest) write(w io.Writer) error {
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// amount returns the amount sent, or nil if the previous block does not
+// allow it to be checked.
+func (req *offlineRequest) amount() (amount *big.Int, err error) {
+	if req.Previous.Type != "state" || req.Previous.Balance == nil {
+		return
+	}
+	hash, err := req.Previous.Hash()
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(hash, req.Block.Previous) {
+		return nil, errors.New("Previous block does not match")
+	}
+	amount = new(big.Int).Sub(&req.Previous.Balance.Int, &req.Block.Balance.Int)
+	switch {
+	case req.Subtype == "send" && amount.Sign() <= 0:
+		return nil, errors.New("Send block does not decrease the balance")
+	case req.Subtype == "change" && amount.Sign() != 0:
+		return nil, errors.New("Change block alters the balance")
+	}
+	return
+}
+
+// verify checks the block's signature against its account.
+func (req *offlineRequest) verify() (hash rpc.BlockHash, err error) {
+	pubkey, err := util.AddressToPubkey(req.Block.Account)
+	if err != nil {
+		return
+	}
+	if hash, err = req.Block.Hash(); err != nil {
+		return
+	}
+	if !ed25519.Verify(pubkey, hash, req.Block.Signature) {
+		return nil, errors.New("Invalid signature")
+	}
+	return
+}
+
+// summary describes the block for the user to check.
+func (req *offlineRequest) summary() (form *widget.Form, err error) {
+	amount, err := req.amount()
+	if err != nil {
+		return
+	}
+	form = widget.NewForm(widget.NewFormItem("From", widget.NewLabel(req.Block.Account)))
+	switch req.Subtype {
+	case "send":
+		recipient, err := util.PubkeyToAddress(req.Block.Link)
+		if err != nil {
+			return nil, err
+		}
+		form.Append("Recipient", newHighlightedAddress(recipient))
+		if amount != nil {
+			form.Append("Amount", widget.NewLabel(rawToNano(amount)+" NANO"))
+		} else {
+			form.Append("Amount", widget.NewLabel("Unknown (previous block is not a state block)"))
+		}
+	case "change":
+		form.Append("Representative", newHighlightedAddress(req.Block.Representative))
+	}
+	form.Append("Balance after", widget.NewLabel(rawToNano(&req.Block.Balance.Int)+" NANO"))
+	form.Append("Previous", widget.NewLabel(req.Block.Previous.String()))
+	return
+}
+
+// signOffline signs the block of a request with the key for account index,
+// without using the network.
+func (wi *walletInfo) signOffline(password string, index uint32, req *offlineRequest) (err error) {
+	if wi.IsLedger {
+		path := []uint32{44, 165, index}
+		if err = ledger.CacheBlock(path, req.Previous); err != nil {
+			return
+		}
+		_, req.Block.Signature, err = ledger.SignBlock(path, req.Block)
+		return
+	}
+	seed, err := wi.decryptSeed(password)
+	if err != nil {
+		return
+	}
+	key, err := deriveAccountKey(seed, index, wi.IsBip39, password)
+	if err != nil {
+		return
+	}
+	pubkey, privkey, err := ed25519.GenerateKey(bytes.NewReader(key))
+	if err != nil {
+		return
+	}
+	address, err := util.PubkeyToAddress(pubkey)
+	if err != nil {
+		return
+	}
+	if address != req.Block.Account {
+		return errors.New("Address mismatch")
+	}
+	hash, err := req.Block.Hash()
+	if err != nil {
+		return
+	}
+	req.Block.Signature = ed25519.Sign(privkey, hash)
+	return
+}
+
+// deriveAccountKey derives the private key seed for an account in the same
+// way as the wallet library.
+func deriveAccountKey(seed []byte, index uint32, isBip39 bool, password string) (key []byte, err error) {
+	if isBip39 {
+		mnemonic, err := bip39.NewMnemonic(seed)
+		if err != nil {
+			return nil, err
+		}
+		if seed, err = bip39.NewSeedWithErrorChecking(mnemonic, password); err != nil {
+			return nil, err
+		}
+		k, err := bip32.NewMasterKey(seed)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range []uint32{44, 165, index} {
+			if k, err = k.NewChildKey(0x80000000 | i); err != nil {
+				return nil, err
+			}
+		}
+		return k.Key, nil
+	}
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		return
+	}
+	h.Write(seed)
+	binary.Write(h, binary.BigEndian, index)
+	return h.Sum(nil), nil
+}
+
+func openOfflineRequest(win fyne.Window, callback func(*offlineRequest)) {
+	d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if r == nil {
+			return
+		}
+		defer r.Close()
+		req, err := readOfflineRequest(r)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		callback(req)
+	}, win)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	d.Show()
+}
+
+func saveOfflineRequest(win fyne.Window, req *offlineRequest) {
+	dialog.ShowFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err == nil && w != nil {
+			err = req.write(w)
+			w.Close()
+		}
+		if err != nil {
+			dialog.ShowError(err, win)
+		}
+	}, win)
+}
+
+// showPrepareOfflineDialog prepares an unsigned send or representative
+// change for ai, to be signed on an offline machine.
+func showPrepareOfflineDialog(win fyne.Window, ai *accountInfo, subtype string) {
+	var (
+		target            = widget.NewEntry()
+		amount            = widget.NewEntry()
+		errLabel, isValid = newAddressError()
+		scroll            = container.NewHScroll(target)
+		form              = widget.NewForm()
+		title             = "Prepare offline send"
+	)
+	scroll.SetMinSize(fyne.NewSize(580, 0))
+	if subtype == "send" {
+		target.SetPlaceHolder("Recipient address")
+		amount.SetPlaceHolder("Amount of NANO")
+		form.Append("Recipient", container.NewVBox(scroll, errLabel))
+		form.Append("Amount", amount)
+	} else {
+		title = "Prepare offline representative change"
+		target.SetPlaceHolder("Representative address")
+		form.Append("New representative", container.NewVBox(scroll, errLabel))
+	}
+	d := newConfirmDialog(title, "Export", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		address, err := validateAddress(target.Text)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		var raw *big.Int
+		if subtype == "send" {
+			n, err := util.NanoAmountFromString(amount.Text)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if raw = n.Raw; raw.Sign() <= 0 {
+				dialog.ShowError(errors.New("Amount must be positive"), win)
+				return
+			}
+		}
+		prog := dialog.NewProgressInfinite(ai.address, "Preparing block...", win)
+		prog.Show()
+		req, err := prepareOfflineRequest(ai.address, subtype, address, raw)
+		prog.Hide()
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		saveOfflineRequest(win, req)
+	}, win)
+	target.OnChanged = func(s string) { d.setValid(isValid(s)) }
+	d.setValid(false)
+	d.Show()
+}
+
+// showSignOfflineDialog signs a request file with the selected wallet.
+func (al *accountList) showSignOfflineDialog(win fyne.Window) {
+	wi := al.wi
+	openOfflineRequest(win, func(req *offlineRequest) {
+		ai := wi.Accounts[req.Block.Account]
+		if ai == nil {
+			dialog.ShowError(errors.New("Account is not in the selected wallet"), win)
+			return
+		}
+		form, err := req.summary()
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		sign := func(password string) (err error) {
+			if err = wi.signOffline(password, ai.Index, req); err != nil {
+				return
+			}
+			if _, err = req.verify(); err != nil {
+				return
+			}
+			saveOfflineRequest(win, req)
+			return
+		}
+		dialog.ShowCustomConfirm("Sign Offline", "Sign", "Cancel", form, func(ok bool) {
+			if !ok {
+				return
+			}
+			if _, err := wi.decryptSeed(""); err != nil && !wi.IsLedger {
+				showPasswordDialog(win, wi.Label, sign)
+				return
+			}
+			if err := sign(""); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+	})
+}
+
+// showPublishSignedDialog publishes a block signed on an offline machine.
+func showPublishSignedDialog(win fyne.Window) {
+	openOfflineRequest(win, func(req *offlineRequest) {
+		hash, err := req.verify()
+		var form *widget.Form
+		if err == nil {
+			form, err = req.summary()
+		}
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		dialog.ShowCustomConfirm("Publish Signed Block", "Publish", "Cancel", form, func(ok bool) {
+			if !ok {
+				return
+			}
+			prog := dialog.NewProgressInfinite("Publish", "Publishing block...", win)
+			prog.Show()
+			rpcClient := rpc.Client{URL: rpcURL}
+			_, err := rpcClient.Process(req.Block, req.Subtype)
+			prog.Hide()
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			trackConfirmation(hash)
+		}, win)
+	})
+}
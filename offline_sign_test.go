@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hectorchu/gonano/util"
+	"github.com/hectorchu/gonano/wallet"
+	"github.com/hectorchu/gonano/wallet/ed25519"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestDeriveAccountKey(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x5a}, 32)
+	mnemonic, err := bip39.NewMnemonic(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const password = "password"
+	w, err := wallet.NewWallet(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wb, err := wallet.NewBip39Wallet(mnemonic, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []uint32{0, 1, 2, 7, 1000, 1<<31 - 1} {
+		for _, tc := range []struct {
+			name    string
+			w       *wallet.Wallet
+			isBip39 bool
+		}{
+			{"seed", w, false},
+			{"bip39", wb, true},
+		} {
+			i := index
+			a, err := tc.w.NewAccount(&i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, err := deriveAccountKey(seed, index, tc.isBip39, password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pubkey, _, err := ed25519.GenerateKey(bytes.NewReader(key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			address, err := util.PubkeyToAddress(pubkey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if address != a.Address() {
+				t.Errorf("%s index %d: got %s, want %s", tc.name, index, address, a.Address())
+			}
+		}
+	}
+}
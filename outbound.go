@@ -0,0 +1,527 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/layout"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+)
+
+const (
+	outboundPublishing = "publishing"
+	outboundPublished  = "published"
+	outboundFailed     = "failed"
+
+	outboundRetryInterval = 10 * time.Second
+	outboundBackoff       = 30 * time.Second
+	outboundMaxBackoff    = 30 * time.Minute
+	outboundMaxAttempts   = 10
+)
+
+// outboundBlock is a signed block which has been sent to a node for
+// publishing but has not yet been seen confirmed.
+type outboundBlock struct {
+	hash      rpc.BlockHash
+	subtype   string
+	block     *rpc.Block
+	status    string
+	attempts  int
+	nextRetry time.Time
+	err       string
+	created   time.Time
+}
+
+var outbound = &outboundQueue{
+	blocks:   make(map[string]*outboundBlock),
+	retry:    make(chan bool, 1),
+	onChange: make(map[int]func()),
+}
+
+// outboundQueue journals every block passed to the process RPC, so that a
+// block is not lost if publishing fails after it has been signed.
+type outboundQueue struct {
+	m         sync.Mutex
+	blocks    map[string]*outboundBlock
+	retry     chan bool
+	onChange  map[int]func()
+	onChangeN int
+}
+
+func init() {
+	bus.subscribe(func(e interface{}) {
+		if e, ok := e.(blockConfirmedEvent); ok {
+			outbound.remove(e.hash)
+		}
+	})
+}
+
+// intercept records the block in a process request before it is forwarded
+// to a node. Other requests are ignored.
+func (q *outboundQueue) intercept(body []byte) {
+	var v struct {
+		Action, Subtype string
+		Block           *rpc.Block
+	}
+	if json.Unmarshal(body, &v) != nil || v.Action != "process" || v.Block == nil {
+		return
+	}
+	hash, err := v.Block.Hash()
+	if err != nil {
+		return
+	}
+	ob := &outboundBlock{
+		hash:      hash,
+		subtype:   v.Subtype,
+		block:     v.Block,
+		status:    outboundPublishing,
+		nextRetry: time.Now().Add(outboundBackoff),
+		created:   time.Now(),
+	}
+	q.m.Lock()
+	if ob2, ok := q.blocks[hash.String()]; ok {
+		ob.attempts, ob.created = ob2.attempts, ob2.created
+	}
+	q.blocks[hash.String()] = ob
+	q.m.Unlock()
+	if err := q.save(ob); err != nil {
+		connLog.add("Outbound journal", err)
+	}
+	q.changed()
+}
+
+func (q *outboundQueue) load() error {
+	return withAppDB(func(db *sql.DB) (err error) {
+		rows, err := db.Query(`SELECT hash, subtype, block, status, attempts, next_retry, error, created
+			FROM outbound_blocks`)
+		if err != nil {
+			return
+		}
+		defer rows.Close()
+		q.m.Lock()
+		defer q.m.Unlock()
+		for rows.Next() {
+			var (
+				hash, block        string
+				nextRetry, created int64
+				ob                 = &outboundBlock{}
+			)
+			if err = rows.Scan(&hash, &ob.subtype, &block, &ob.status, &ob.attempts,
+				&nextRetry, &ob.err, &created); err != nil {
+				return
+			}
+			if ob.hash, err = hex.DecodeString(hash); err != nil {
+				return
+			}
+			if err = json.Unmarshal([]byte(block), &ob.block); err != nil {
+				return
+			}
+			ob.nextRetry, ob.created = time.Unix(nextRetry, 0), time.Unix(created, 0)
+			q.blocks[hash] = ob
+		}
+		return rows.Err()
+	})
+}
+
+func (q *outboundQueue) save(ob *outboundBlock) error {
+	q.m.Lock()
+	block, err := json.Marshal(ob.block)
+	args := []interface{}{
+		ob.hash.String(), ob.subtype, string(block), ob.status,
+		ob.attempts, ob.nextRetry.Unix(), ob.err, ob.created.Unix(),
+	}
+	q.m.Unlock()
+	if err != nil {
+		return err
+	}
+	return withAppDB(func(db *sql.DB) (err error) {
+		_, err = db.Exec(`INSERT OR REPLACE INTO outbound_blocks
+			(hash, subtype, block, status, attempts, next_retry, error, created)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, args...)
+		return
+	})
+}
+
+// remove drops a block from the journal, once confirmed or discarded.
+func (q *outboundQueue) remove(hash rpc.BlockHash) {
+	q.m.Lock()
+	_, ok := q.blocks[hash.String()]
+	delete(q.blocks, hash.String())
+	q.m.Unlock()
+	if !ok {
+		return
+	}
+	if err := withAppDB(func(db *sql.DB) (err error) {
+		_, err = db.Exec("DELETE FROM outbound_blocks WHERE hash = ?", hash.String())
+		return
+	}); err != nil {
+		connLog.add("Outbound journal", err)
+	}
+	q.changed()
+}
+
+func (q *outboundQueue) list() (blocks []*outboundBlock) {
+	q.m.Lock()
+	for _, ob := range q.blocks {
+		ob2 := *ob
+		blocks = append(blocks, &ob2)
+	}
+	q.m.Unlock()
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].created.Before(blocks[j].created) })
+	return
+}
+
+// get returns a copy of the journal entry for hash, if any.
+func (q *outboundQueue) get(hash rpc.BlockHash) *outboundBlock {
+	q.m.Lock()
+	defer q.m.Unlock()
+	if ob, ok := q.blocks[hash.String()]; ok {
+		ob2 := *ob
+		return &ob2
+	}
+	return nil
+}
+
+func (q *outboundQueue) count() int {
+	q.m.Lock()
+	defer q.m.Unlock()
+	return len(q.blocks)
+}
+
+func (q *outboundQueue) onChanged(f func()) (key int) {
+	q.m.Lock()
+	key = q.onChangeN
+	q.onChangeN++
+	q.onChange[key] = f
+	q.m.Unlock()
+	return
+}
+
+func (q *outboundQueue) offChanged(key int) {
+	q.m.Lock()
+	delete(q.onChange, key)
+	q.m.Unlock()
+}
+
+func (q *outboundQueue) changed() {
+	q.m.Lock()
+	onChange := make([]func(), 0, len(q.onChange))
+	for _, f := range q.onChange {
+		onChange = append(onChange, f)
+	}
+	q.m.Unlock()
+	for _, f := range onChange {
+		f()
+	}
+}
+
+// retryNow schedules a block to be republished immediately.
+func (q *outboundQueue) retryNow(hash rpc.BlockHash) {
+	q.m.Lock()
+	ob, ok := q.blocks[hash.String()]
+	if ok {
+		ob.status, ob.nextRetry = outboundPublishing, time.Now()
+	}
+	q.m.Unlock()
+	if ok {
+		q.save(ob)
+		select {
+		case q.retry <- true:
+		default:
+		}
+	}
+}
+
+// run republishes journalled blocks which are due for a retry.
+func (q *outboundQueue) run() {
+	if err := q.load(); err != nil {
+		connLog.add("Outbound journal", err)
+	}
+	q.changed()
+	ticker := time.NewTicker(outboundRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-q.retry:
+		}
+		var due []*outboundBlock
+		q.m.Lock()
+		for _, ob := range q.blocks {
+			if ob.status != outboundFailed && !time.Now().Before(ob.nextRetry) {
+				due = append(due, ob)
+			}
+		}
+		due = chainOrder(due)
+		q.m.Unlock()
+		unpublished := make(map[string]bool)
+		for _, ob := range due {
+			// A block cannot be published before the block it builds on.
+			if unpublished[ob.block.Previous.String()] || !q.attempt(ob) {
+				unpublished[ob.hash.String()] = true
+			}
+		}
+	}
+}
+
+// chainOrder sorts blocks oldest first, moving each block after the block it
+// builds on so that chains from the same account are published in order.
+func chainOrder(blocks []*outboundBlock) (ordered []*outboundBlock) {
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].created.Before(blocks[j].created) })
+	byHash := make(map[string]*outboundBlock)
+	for _, ob := range blocks {
+		byHash[ob.hash.String()] = ob
+	}
+	visited := make(map[string]bool)
+	var visit func(*outboundBlock)
+	visit = func(ob *outboundBlock) {
+		if visited[ob.hash.String()] {
+			return
+		}
+		visited[ob.hash.String()] = true
+		if prev, ok := byHash[ob.block.Previous.String()]; ok {
+			visit(prev)
+		}
+		ordered = append(ordered, ob)
+	}
+	for _, ob := range blocks {
+		visit(ob)
+	}
+	return
+}
+
+// attempt checks whether a block has reached the ledger and republishes it
+// if not, trying a different node on each attempt. It reports whether the
+// block is now known to a node.
+func (q *outboundQueue) attempt(ob *outboundBlock) (published bool) {
+	rpcClient := rpc.Client{URL: rpcURL}
+	if bi, err := rpcClient.BlockInfo(ob.hash); err == nil {
+		if bi.Confirmed {
+			q.remove(ob.hash)
+			return true
+		}
+		q.update(ob.hash, outboundPublished, nil)
+		return true
+	}
+	q.m.Lock()
+	attempts := ob.attempts
+	block := *ob.block
+	q.m.Unlock()
+	urls := nodes.getURLs()
+	if len(urls) == 0 {
+		return
+	}
+	// The current node has most likely just failed, so start with the next.
+	start, current := 0, nodes.current()
+	for i, url := range urls {
+		if url == current {
+			start = i + 1
+			break
+		}
+	}
+	node := urls[(start+attempts)%len(urls)]
+	err := republish(node, &block, ob.subtype)
+	switch {
+	case err == nil:
+		q.update(ob.hash, outboundPublished, nil)
+		return true
+	case isForkError(err):
+		q.update(ob.hash, outboundFailed, err)
+	case attempts+1 >= outboundMaxAttempts:
+		q.update(ob.hash, outboundFailed, err)
+	default:
+		q.update(ob.hash, outboundPublishing, err)
+	}
+	return
+}
+
+// republish sends a block to a specific node, generating fresh work if the
+// node rejects the block's work.
+func republish(node string, block *rpc.Block, subtype string) (err error) {
+	rpcClient := rpc.Client{URL: node}
+	if _, err = rpcClient.Process(block, subtype); err == nil {
+		return
+	}
+	if !strings.Contains(strings.ToLower(err.Error()), "work") {
+		return fmt.Errorf("%s: %v", node, err)
+	}
+	if err = publishBlock(block, subtype); err != nil {
+		return fmt.Errorf("%s: %v", node, err)
+	}
+	return
+}
+
+// update records the outcome of an attempt and schedules the next one. The
+// journal entry may have been replaced while the attempt was in progress.
+func (q *outboundQueue) update(hash rpc.BlockHash, status string, err error) {
+	q.m.Lock()
+	ob, ok := q.blocks[hash.String()]
+	if !ok {
+		q.m.Unlock()
+		return
+	}
+	ob.status = status
+	ob.attempts++
+	backoff := outboundBackoff << uint(ob.attempts)
+	if backoff > outboundMaxBackoff || backoff <= 0 {
+		backoff = outboundMaxBackoff
+	}
+	ob.nextRetry = time.Now().Add(backoff)
+	if err != nil {
+		ob.err = err.Error()
+	}
+	q.m.Unlock()
+	if err := q.save(ob); err != nil {
+		connLog.add("Outbound journal", err)
+	}
+	q.changed()
+}
+
+// newOutboundWindow shows journalled blocks which have not been confirmed,
+// and lets the user retry or discard them. Journal changes are coalesced and
+// applied to the list by a goroutine owned by the window.
+func newOutboundWindow() {
+	var (
+		win        = fyne.CurrentApp().NewWindow("Pending Outbound Blocks")
+		m          sync.Mutex
+		blocks     = outbound.list()
+		selectedID = -1
+		selected   rpc.BlockHash
+		list       *widget.List
+		changed    = make(chan bool, 1)
+		closed     = make(chan bool)
+	)
+	getBlock := func(id int) *outboundBlock {
+		m.Lock()
+		defer m.Unlock()
+		if id < 0 || id >= len(blocks) {
+			return nil
+		}
+		return blocks[id]
+	}
+	getSelected := func() rpc.BlockHash {
+		m.Lock()
+		defer m.Unlock()
+		return selected
+	}
+	list = widget.NewList(
+		func() int {
+			m.Lock()
+			defer m.Unlock()
+			return len(blocks)
+		},
+		func() fyne.CanvasObject {
+			return fyne.NewContainerWithLayout(
+				newHBoxLayout([]int{560, 80, 100, 160, 80}), newCopyableLabel(win, ""),
+				newCopyableLabel(win, ""), newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+				newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			ob := getBlock(id)
+			if ob == nil {
+				return
+			}
+			getLabel := func(i int) *contextMenuLabel {
+				return item.(*fyne.Container).Objects[i].(*contextMenuLabel)
+			}
+			getLabel(0).SetText(ob.hash.String())
+			getLabel(1).SetText(ob.subtype)
+			getLabel(2).SetText(ob.status)
+			getLabel(3).SetText(ob.created.Local().Format("2006-01-02 15:04:05"))
+			getLabel(4).SetText(fmt.Sprintf("%d tries", ob.attempts))
+			getLabel(5).SetText(ob.err)
+			for i := 0; i < 6; i++ {
+				getLabel(i).tapped = func() { list.Select(id) }
+			}
+		},
+	)
+	var (
+		retryButton = widget.NewButtonWithIcon("Retry", theme.ViewRefreshIcon(), func() {
+			if hash := getSelected(); hash != nil {
+				outbound.retryNow(hash)
+			}
+		})
+		discardButton = widget.NewButtonWithIcon("Discard", theme.DeleteIcon(), func() {
+			hash := getSelected()
+			if hash == nil {
+				return
+			}
+			dialog.ShowConfirm("Discard block?",
+				"The block will no longer be retried. It may still be confirmed if a node has it.",
+				func(ok bool) {
+					if ok {
+						outbound.remove(hash)
+					}
+				}, win)
+		})
+	)
+	setSelected := func(id int) {
+		ob := getBlock(id)
+		m.Lock()
+		if ob != nil {
+			selectedID, selected = id, ob.hash
+		} else {
+			selectedID, selected = -1, nil
+		}
+		m.Unlock()
+		if ob != nil {
+			retryButton.Enable()
+			discardButton.Enable()
+		} else {
+			retryButton.Disable()
+			discardButton.Disable()
+		}
+	}
+	list.OnSelected = func(id widget.ListItemID) { setSelected(id) }
+	list.OnUnselected = func(id widget.ListItemID) { setSelected(-1) }
+	setSelected(-1)
+	go func() {
+		for {
+			select {
+			case <-changed:
+			case <-closed:
+				return
+			}
+			m.Lock()
+			id := selectedID
+			m.Unlock()
+			if id >= 0 {
+				list.Unselect(id)
+			}
+			m.Lock()
+			blocks = outbound.list()
+			m.Unlock()
+			list.Refresh()
+		}
+	}()
+	key := outbound.onChanged(func() {
+		select {
+		case changed <- true:
+		default:
+		}
+	})
+	win.SetOnClosed(func() {
+		outbound.offChanged(key)
+		close(closed)
+	})
+	win.SetContent(container.NewBorder(
+		widget.NewLabel("Signed blocks not yet confirmed:"),
+		widget.NewHBox(retryButton, discardButton, layout.NewSpacer()),
+		nil, nil, list,
+	))
+	win.Resize(fyne.NewSize(1400, 400))
+	win.CenterOnScreen()
+	win.Show()
+}
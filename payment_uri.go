@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/hectorchu/gonano/util"
+)
+
+// paymentRequest is a parsed nano: payment URI.
+type paymentRequest struct {
+	address        string
+	amount         *big.Int
+	label, message string
+}
+
+func isPaymentURI(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.HasPrefix(s, "nano:") || strings.HasPrefix(s, "xrb:")
+}
+
+// parsePaymentURI parses URIs of the form
+// nano:<address>?amount=<raw>&label=<label>&message=<message>.
+func parsePaymentURI(s string) (pr *paymentRequest, err error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "nano" && scheme != "xrb" {
+		return nil, errors.New("Not a nano payment URI")
+	}
+	address := u.Opaque
+	if address == "" {
+		address = u.Host + strings.TrimPrefix(u.Path, "/")
+	}
+	pubkey, err := util.AddressToPubkey(address)
+	if err != nil {
+		return
+	}
+	pr = &paymentRequest{}
+	if pr.address, err = util.PubkeyToAddress(pubkey); err != nil {
+		return
+	}
+	q := u.Query()
+	if amount := q.Get("amount"); amount != "" {
+		var ok bool
+		if pr.amount, ok = new(big.Int).SetString(amount, 10); !ok || pr.amount.Sign() < 0 {
+			return nil, errors.New("Invalid amount in payment URI")
+		}
+	}
+	pr.label, pr.message = q.Get("label"), q.Get("message")
+	return
+}
+
+// rawToNano formats a raw amount as NANO without losing precision.
+func rawToNano(raw *big.Int) string {
+	s := tcm.amountToString(raw, 30)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// paymentURI formats a nano: payment URI. amount may be nil.
+func paymentURI(address string, amount *big.Int, label, message string) string {
+	q := make(url.Values)
+	if amount != nil {
+		q.Set("amount", amount.String())
+	}
+	if label != "" {
+		q.Set("label", label)
+	}
+	if message != "" {
+		q.Set("message", message)
+	}
+	uri := "nano:" + address
+	if len(q) > 0 {
+		uri += "?" + strings.Replace(q.Encode(), "+", "%20", -1)
+	}
+	return uri
+}
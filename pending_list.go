@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/layout"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+)
+
+type pendingList struct {
+	wi            *walletInfo
+	ai            *accountInfo
+	list          *widget.List
+	receiveButton *widget.Button
+	summary       *widget.Label
+	pendings      []*pendingBlock
+}
+
+// newPendingList shows the pending blocks of an account and lets the user
+// choose which of them to receive.
+func newPendingList(wi *walletInfo, ai *accountInfo) (pl *pendingList) {
+	win := fyne.CurrentApp().NewWindow("Pending for " + ai.address)
+	pl = &pendingList{
+		wi: wi,
+		ai: ai,
+		list: widget.NewList(
+			func() int { return len(pl.pendings) },
+			func() fyne.CanvasObject {
+				return fyne.NewContainerWithLayout(
+					newHBoxLayout([]int{40, 600, 200, 80}), widget.NewCheck("", nil),
+					newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+					widget.NewLabel(""), newCopyableLabel(win, ""),
+				)
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				if id >= len(pl.pendings) {
+					return
+				}
+				pb := pl.pendings[id]
+				objects := item.(*fyne.Container).Objects
+				check := objects[0].(*widget.Check)
+				check.OnChanged = nil
+				check.SetChecked(pb.receive)
+				check.OnChanged = func(checked bool) {
+					pb.receive = checked
+					pl.update()
+				}
+				source := pb.source
+				if c := addressBook.lookup(source); c != nil {
+					source += " (" + c.Name + ")"
+				}
+				objects[1].(*contextMenuLabel).SetText(source)
+				objects[2].(*contextMenuLabel).SetText(rawToNano(pb.amount))
+				dust := ""
+				if wi.isDust(ai, pb.amount) {
+					dust = "Dust"
+				}
+				objects[3].(*widget.Label).SetText(dust)
+				objects[4].(*contextMenuLabel).SetText(pb.hash.String())
+			},
+		),
+		summary: widget.NewLabel(""),
+	}
+	pl.receiveButton = widget.NewButtonWithIcon("Receive Selected", theme.MailReplyIcon(), func() {
+		if err := pl.receive(win); err != nil {
+			dialog.ShowError(err, win)
+		}
+		pl.load(win)
+	})
+	selectAll := func(receive bool) func() {
+		return func() {
+			for _, pb := range pl.pendings {
+				pb.receive = receive
+			}
+			pl.update()
+		}
+	}
+	win.SetContent(container.NewBorder(
+		widget.NewLabel("Pending blocks:"),
+		widget.NewHBox(
+			widget.NewButton("Select All", selectAll(true)),
+			widget.NewButton("Select None", selectAll(false)),
+			pl.receiveButton,
+			widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), func() { pl.load(win) }),
+			layout.NewSpacer(), pl.summary,
+		),
+		nil, nil, pl.list,
+	))
+	win.Resize(fyne.NewSize(1600, 600))
+	win.CenterOnScreen()
+	win.Show()
+	pl.load(win)
+	return
+}
+
+func (pl *pendingList) load(win fyne.Window) {
+	prog := dialog.NewProgressInfinite("Pending", "Loading pending blocks...", win)
+	prog.Show()
+	receive, dust, err := pl.wi.getPendings([]*accountInfo{pl.ai})
+	prog.Hide()
+	if err != nil {
+		dialog.ShowError(err, win)
+	}
+	pl.pendings = append(receive, dust...)
+	pl.update()
+}
+
+func (pl *pendingList) selected() (blocks []*pendingBlock) {
+	for _, pb := range pl.pendings {
+		if pb.receive {
+			blocks = append(blocks, pb)
+		}
+	}
+	return
+}
+
+func (pl *pendingList) update() {
+	selected := pl.selected()
+	pl.summary.SetText(fmt.Sprintf("%d of %d selected, %s NANO",
+		len(selected), len(pl.pendings), rawToNano(pendingTotal(selected)),
+	))
+	if len(selected) > 0 && !pl.wi.IsWatchOnly && pl.wi.w != nil {
+		pl.receiveButton.Enable()
+	} else {
+		pl.receiveButton.Disable()
+	}
+	pl.list.Refresh()
+}
+
+func (pl *pendingList) receive(win fyne.Window) (err error) {
+	prog := dialog.NewProgressInfinite(pl.wi.Label, "Receiving selected blocks...", win)
+	prog.Show()
+	err = pl.wi.receivePendings(pl.selected())
+	prog.Hide()
+	return
+}
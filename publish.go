@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/hectorchu/gonano/util"
+)
+
+// publishBlock generates work for a signed block and publishes it.
+func publishBlock(block *rpc.Block, subtype string) (err error) {
+	difficulty := sendDifficulty
+	if subtype == "receive" || subtype == "open" {
+		difficulty = receiveDifficulty
+	}
+	root := block.Previous
+	if isZero(root) {
+		if root, err = util.AddressToPubkey(block.Account); err != nil {
+			return
+		}
+	}
+	workClient := rpc.Client{URL: workURL}
+	if block.Work, _, _, err = workClient.WorkGenerate(root, difficulty); err != nil {
+		return
+	}
+	rpcClient := rpc.Client{URL: rpcURL}
+	_, err = rpcClient.Process(block, subtype)
+	return
+}
+
+// blockExists reports whether the node knows of a block.
+func blockExists(hash rpc.BlockHash) bool {
+	rpcClient := rpc.Client{URL: rpcURL}
+	_, err := rpcClient.BlockInfo(hash)
+	return err == nil
+}
+
+// isForkError reports whether a node rejected a block because another block
+// already follows its previous block.
+func isForkError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "fork")
+}
+
+func isZero(b []byte) bool {
+	for _, x := range b {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
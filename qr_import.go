@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/storage"
+	"fyne.io/fyne/theme"
+	"github.com/hectorchu/gonano/util"
+	"github.com/makiuchi-d/gozxing"
+	qrreader "github.com/makiuchi-d/gozxing/qrcode"
+	"golang.design/x/clipboard"
+)
+
+// newQRImportButton returns a button which decodes a QR code from an image
+// file or the clipboard, and passes the address or payment request to
+// callback.
+func newQRImportButton(win fyne.Window, callback func(*paymentRequest)) *contextMenuButton {
+	decode := func(r io.Reader) {
+		pr, err := decodePaymentQR(r)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		callback(pr)
+	}
+	return newContextMenuButton("", theme.FileImageIcon(), fyne.NewMenu("",
+		fyne.NewMenuItem("Import from QR image file", func() {
+			d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+				if err != nil {
+					dialog.ShowError(err, win)
+					return
+				}
+				if r == nil {
+					return
+				}
+				defer r.Close()
+				decode(r)
+			}, win)
+			d.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg"}))
+			d.Show()
+		}),
+		fyne.NewMenuItem("Import from QR image in clipboard", func() {
+			r, err := clipboardImage(win.Clipboard())
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if c, ok := r.(io.Closer); ok {
+				defer c.Close()
+			}
+			decode(r)
+		}),
+	))
+}
+
+var (
+	clipboardOnce sync.Once
+	clipboardErr  error
+)
+
+// clipboardImage returns the image data in the clipboard. If the clipboard
+// holds no image, its text is tried as a data URI, a file URI or a file path.
+func clipboardImage(cb fyne.Clipboard) (r io.Reader, err error) {
+	clipboardOnce.Do(func() { clipboardErr = clipboard.Init() })
+	if clipboardErr == nil {
+		if data := clipboard.Read(clipboard.FmtImage); len(data) > 0 {
+			return bytes.NewReader(data), nil
+		}
+	}
+	return clipboardImageText(cb.Content())
+}
+
+// clipboardImageText opens the image referred to by the clipboard text.
+func clipboardImageText(s string) (r io.Reader, err error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, errors.New("Clipboard does not contain an image")
+	case strings.HasPrefix(s, "data:image/"):
+		i := strings.Index(s, ";base64,")
+		if i < 0 {
+			return nil, errors.New("Unsupported data URI in clipboard")
+		}
+		data, err := base64.StdEncoding.DecodeString(s[i+len(";base64,"):])
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(data), nil
+	case strings.HasPrefix(s, "file://"):
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		s = u.Path
+	}
+	f, err := os.Open(s)
+	if err != nil {
+		return nil, errors.New("Clipboard does not contain an image or image file path")
+	}
+	return f, nil
+}
+
+// decodePaymentQR decodes a QR code containing an address or a nano:
+// payment URI.
+func decodePaymentQR(r io.Reader) (pr *paymentRequest, err error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return
+	}
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return
+	}
+	result, err := qrreader.NewQRCodeReader().Decode(bmp, map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	})
+	if err != nil {
+		return nil, errors.New("No QR code found")
+	}
+	text := strings.TrimSpace(result.GetText())
+	if isPaymentURI(text) {
+		return parsePaymentURI(text)
+	}
+	pubkey, err := util.AddressToPubkey(text)
+	if err != nil {
+		return nil, errors.New("QR code does not contain a Nano address")
+	}
+	pr = &paymentRequest{}
+	pr.address, err = util.PubkeyToAddress(pubkey)
+	return
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"image"
+	"math/big"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/canvas"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/util"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const qrSize = 256
+
+func showReceiveDialog(win fyne.Window, ai *accountInfo) {
+	var (
+		uri        string
+		amount     = widget.NewEntry()
+		label      = widget.NewEntry()
+		message    = widget.NewEntry()
+		blank      = image.NewGray(image.Rect(0, 0, 1, 1))
+		qr         = canvas.NewImageFromImage(blank)
+		uriText    = newCopyableLabel(win, "")
+		errText    = widget.NewLabel("")
+		copyButton = widget.NewButton("Copy URI", func() { win.Clipboard().SetContent(uri) })
+		saveButton = widget.NewButton("Save PNG", func() {
+			png, err := qrcode.Encode(uri, qrcode.Medium, qrSize)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			dialog.ShowFileSave(func(w fyne.URIWriteCloser, err error) {
+				if err != nil {
+					dialog.ShowError(err, win)
+					return
+				}
+				if w == nil {
+					return
+				}
+				defer w.Close()
+				if _, err = w.Write(png); err != nil {
+					dialog.ShowError(err, win)
+				}
+			}, win)
+		})
+		// invalid clears the payment request, so that a stale one cannot
+		// be copied or saved while the input is wrong.
+		invalid = func(msg string) {
+			errText.SetText(msg)
+			uri = ""
+			uriText.SetText("")
+			qr.Image = blank
+			qr.Refresh()
+			copyButton.Disable()
+			saveButton.Disable()
+		}
+		update = func(string) {
+			var raw *big.Int
+			if amount.Text != "" {
+				n, err := util.NanoAmountFromString(amount.Text)
+				if err != nil || n.Raw.Sign() < 0 {
+					invalid("Invalid amount")
+					return
+				}
+				raw = n.Raw
+			}
+			s := paymentURI(ai.address, raw, label.Text, message.Text)
+			q, err := qrcode.New(s, qrcode.Medium)
+			if err != nil {
+				invalid(err.Error())
+				return
+			}
+			errText.SetText("")
+			uri = s
+			uriText.SetText(uri)
+			qr.Image = q.Image(qrSize)
+			qr.Refresh()
+			copyButton.Enable()
+			saveButton.Enable()
+		}
+		form = widget.NewForm(
+			widget.NewFormItem("Amount", amount),
+			widget.NewFormItem("Label", label),
+			widget.NewFormItem("Message", message),
+		)
+	)
+	amount.SetPlaceHolder("Requested amount of NANO (optional)")
+	label.SetPlaceHolder("Optional")
+	message.SetPlaceHolder("Optional")
+	amount.OnChanged, label.OnChanged, message.OnChanged = update, update, update
+	qr.FillMode = canvas.ImageFillContain
+	qr.SetMinSize(fyne.NewSize(qrSize, qrSize))
+	update("")
+	content := container.NewVBox(
+		widget.NewLabel(ai.address),
+		qr,
+		container.NewHScroll(uriText),
+		form,
+		errText,
+		container.NewHBox(copyButton, saveButton),
+	)
+	d := dialog.NewCustom("Receive Details", "Close", content, win)
+	d.Show()
+	d.Resize(fyne.NewSize(700, 0))
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/hectorchu/gonano/util"
+)
+
+// pendingBlock is a send waiting to be received by one of our accounts.
+// receive marks it as selected for receiving.
+type pendingBlock struct {
+	ai      *accountInfo
+	source  string
+	hash    rpc.BlockHash
+	amount  *big.Int
+	receive bool
+}
+
+// receiveMinimum returns the smallest pending amount that will be received
+// by ai, or nil if there is no minimum. An account's own setting overrides
+// the wallet's.
+func (wi *walletInfo) receiveMinimum(ai *accountInfo) *big.Int {
+	s := wi.ReceiveMinimum
+	if ai != nil && ai.ReceiveMinimum != "" {
+		s = ai.ReceiveMinimum
+	}
+	if s == "" {
+		return nil
+	}
+	n, err := util.NanoAmountFromString(s)
+	if err != nil {
+		return nil
+	}
+	return n.Raw
+}
+
+// isDust reports whether amount is below the receive minimum for ai.
+func (wi *walletInfo) isDust(ai *accountInfo, amount *big.Int) bool {
+	min := wi.receiveMinimum(ai)
+	return min != nil && amount.Cmp(min) < 0
+}
+
+// getPendings returns the pending blocks of the given accounts, split into
+// those to receive and those below the receive minimum.
+func (wi *walletInfo) getPendings(accounts []*accountInfo) (receive, dust []*pendingBlock, err error) {
+	addresses := make([]string, len(accounts))
+	for i, ai := range accounts {
+		addresses[i] = ai.address
+	}
+	rpcClient := rpc.Client{URL: rpcURL}
+	pendings, err := rpcClient.AccountsPending(addresses, -1)
+	if err != nil {
+		return
+	}
+	for _, ai := range accounts {
+		for hash, pending := range pendings[ai.address] {
+			pb := &pendingBlock{ai: ai, source: pending.Source, amount: &pending.Amount.Int}
+			if pb.hash, err = hex.DecodeString(hash); err != nil {
+				return
+			}
+			if wi.isDust(ai, pb.amount) {
+				dust = append(dust, pb)
+			} else {
+				receive = append(receive, pb)
+			}
+		}
+	}
+	for _, v := range [][]*pendingBlock{receive, dust} {
+		sort.SliceStable(v, func(i, j int) bool {
+			if v[i].ai != v[j].ai {
+				return wi.indexOf(v[i].ai) < wi.indexOf(v[j].ai)
+			}
+			return v[i].amount.Cmp(v[j].amount) > 0
+		})
+	}
+	return
+}
+
+// receivePendings pockets the given pending blocks.
+func (wi *walletInfo) receivePendings(blocks []*pendingBlock) (err error) {
+	for _, pb := range blocks {
+		a, err := wi.w.NewAccount(&pb.ai.Index)
+		if err != nil {
+			return err
+		}
+		if a.Address() != pb.ai.address {
+			return errors.New("Address mismatch")
+		}
+		if err = withAccountLock(pb.ai.address, func() (err error) {
+			_, err = a.ReceivePending(pb.hash)
+			return
+		}); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func pendingTotal(blocks []*pendingBlock) *big.Int {
+	total := new(big.Int)
+	for _, pb := range blocks {
+		total.Add(total, pb.amount)
+	}
+	return total
+}
+
+// receivePendingsOf receives the pending blocks of accounts that are above
+// the receive minimum, then offers to receive any ignored dust.
+func (al *accountList) receivePendingsOf(win fyne.Window, accounts []*accountInfo) (err error) {
+	wi := al.wi
+	prog := dialog.NewProgressInfinite(wi.Label, "Receiving pending amounts...", win)
+	prog.Show()
+	receive, dust, err := wi.getPendings(accounts)
+	if err == nil {
+		err = wi.receivePendings(receive)
+	}
+	prog.Hide()
+	if err == nil && len(dust) > 0 {
+		showDustDialog(win, wi, dust)
+	}
+	return
+}
+
+// showDustDialog lists pending blocks that were ignored for being below the
+// receive minimum, with the option to receive them anyway.
+func showDustDialog(win fyne.Window, wi *walletInfo, dust []*pendingBlock) {
+	list := widget.NewList(
+		func() int { return len(dust) },
+		func() fyne.CanvasObject {
+			return fyne.NewContainerWithLayout(
+				newHBoxLayout([]int{600, 600}), newCopyableLabel(win, ""),
+				newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			pb := dust[id]
+			objects := item.(*fyne.Container).Objects
+			objects[0].(*contextMenuLabel).SetText(pb.ai.address)
+			objects[1].(*contextMenuLabel).SetText(pb.source)
+			objects[2].(*contextMenuLabel).SetText(rawToNano(pb.amount))
+		},
+	)
+	label := widget.NewLabel(fmt.Sprintf(
+		"%d pending blocks totalling %s NANO are below the minimum receive amount and were ignored.",
+		len(dust), rawToNano(pendingTotal(dust)),
+	))
+	content := container.NewBorder(label, nil, nil, nil, list)
+	d := dialog.NewCustomConfirm("Ignored Dust", "Receive Anyway", "Close", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		prog := dialog.NewProgressInfinite(wi.Label, "Receiving pending amounts...", win)
+		prog.Show()
+		err := wi.receivePendings(dust)
+		prog.Hide()
+		if err != nil {
+			dialog.ShowError(err, win)
+		}
+	}, win)
+	d.Show()
+	d.Resize(fyne.NewSize(1400, 400))
+}
+
+// showReceiveMinimumDialog edits the receive minimum of a wallet, or of one
+// of its accounts if ai is not nil.
+func showReceiveMinimumDialog(win fyne.Window, wl *walletList, wi *walletInfo, ai *accountInfo) {
+	var (
+		amount  = widget.NewEntry()
+		scroll  = container.NewHScroll(amount)
+		content = widget.NewForm(widget.NewFormItem("Minimum (NANO)", scroll))
+		title   = "Minimum receive amount for " + wi.Label
+		current = &wi.ReceiveMinimum
+	)
+	amount.SetPlaceHolder("No minimum")
+	if ai != nil {
+		title = "Minimum receive amount for " + ai.address
+		current = &ai.ReceiveMinimum
+		if wi.ReceiveMinimum != "" {
+			amount.SetPlaceHolder("Wallet default (" + wi.ReceiveMinimum + ")")
+		}
+	}
+	amount.SetText(*current)
+	scroll.SetMinSize(fyne.NewSize(300, 0))
+	dialog.ShowCustomConfirm(title, "OK", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if amount.Text != "" {
+			n, err := util.NanoAmountFromString(amount.Text)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if n.Raw.Sign() < 0 {
+				dialog.ShowError(errors.New("Minimum must not be negative"), win)
+				return
+			}
+		}
+		*current = amount.Text
+		if err := wl.saveWallet(wi); err != nil {
+			dialog.ShowError(err, win)
+		}
+	}, win)
+}
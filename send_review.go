@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/canvas"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/hectorchu/gonano/util"
+)
+
+// addressCheckLength is the number of characters at each end of an address
+// that are highlighted for visual checking.
+const addressCheckLength = 8
+
+// showSendReview shows a summary of a send for the user to check before the
+// block is signed. pr is the payment request the send came from, if any.
+func (al *accountList) showSendReview(win fyne.Window, account, amount string, pr *paymentRequest, confirm func()) (err error) {
+	pubkey, err := util.AddressToPubkey(account)
+	if err != nil {
+		return
+	}
+	if account, err = util.PubkeyToAddress(pubkey); err != nil {
+		return
+	}
+	n, err := util.NanoAmountFromString(amount)
+	if err != nil {
+		return
+	}
+	if n.Raw.Sign() <= 0 {
+		return errors.New("Amount must be positive")
+	}
+	prog := dialog.NewProgressInfinite("Review", "Checking recipient...", win)
+	prog.Show()
+	warnings, balance := al.sendWarnings(account, n.Raw)
+	prog.Hide()
+	form := widget.NewForm()
+	if pr != nil && pr.address == account {
+		if pr.label != "" {
+			form.Append("Pay to", widget.NewLabel(pr.label))
+		}
+		if pr.message != "" {
+			form.Append("Message", widget.NewLabel(pr.message))
+		}
+	}
+	form.Append("From", widget.NewLabel(al.selectedAccount.address))
+	form.Append("Recipient", newHighlightedAddress(account))
+	form.Append("Amount", widget.NewLabel(rawToNano(n.Raw)+" NANO"))
+	form.Append("Amount (raw)", widget.NewLabel(n.Raw.String()))
+	if balance != nil {
+		form.Append("Balance after", widget.NewLabel(rawToNano(new(big.Int).Sub(balance, n.Raw))+" NANO"))
+	}
+	content := container.NewVBox(form)
+	for _, w := range warnings {
+		l := widget.NewLabelWithStyle("Warning: "+w, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		content.Add(l)
+	}
+	dialog.ShowCustomConfirm("Review Send", "Send", "Cancel", content, func(ok bool) {
+		if ok {
+			confirm()
+		}
+	}, win)
+	return
+}
+
+// sendWarnings checks the recipient and amount, and also returns the current
+// balance of the sending account if known.
+func (al *accountList) sendWarnings(account string, amount *big.Int) (warnings []string, balance *big.Int) {
+	if account == al.selectedAccount.address {
+		warnings = append(warnings, "Recipient is the sending account")
+	} else if wi := al.wl.findAccount(account); wi != nil {
+		warnings = append(warnings, "Recipient is one of your own accounts (wallet "+wi.Label+")")
+	}
+	if tcm.isChainAddress(account) {
+		warnings = append(warnings, "Recipient is a token chain address")
+	}
+	rpcClient := rpc.Client{URL: rpcURL}
+	if b, _, err := rpcClient.AccountBalance(al.selectedAccount.address); err == nil {
+		balance = &b.Int
+	} else {
+		al.m.Lock()
+		balance = al.selectedAccount.balance.Raw
+		al.m.Unlock()
+	}
+	if balance != nil && amount.Cmp(balance) > 0 {
+		warnings = append(warnings, "Amount exceeds the account balance")
+	}
+	if frontiers, err := rpcClient.AccountsFrontiers([]string{account}); err == nil {
+		if _, ok := frontiers[account]; !ok {
+			warnings = append(warnings, "Recipient account has never been opened")
+		}
+	}
+	return
+}
+
+// newHighlightedAddress renders an address with its first and last
+// characters emphasised.
+func newHighlightedAddress(address string) fyne.CanvasObject {
+	i := len("nano_") + addressCheckLength
+	j := len(address) - addressCheckLength
+	if j < i {
+		i, j = len(address), len(address)
+	}
+	var (
+		style = fyne.TextStyle{Monospace: true}
+		bold  = fyne.TextStyle{Monospace: true, Bold: true}
+		head  = canvas.NewText(address[:i], theme.PrimaryColor())
+		mid   = canvas.NewText(address[i:j], theme.TextColor())
+		tail  = canvas.NewText(address[j:], theme.PrimaryColor())
+	)
+	head.TextStyle, mid.TextStyle, tail.TextStyle = bold, style, bold
+	return fyne.NewContainerWithLayout(textRunLayout{}, head, mid, tail)
+}
+
+// textRunLayout places text objects side by side without padding.
+type textRunLayout struct{}
+
+func (textRunLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	x := 0
+	for _, o := range objects {
+		min := o.MinSize()
+		o.Move(fyne.NewPos(x, (size.Height-min.Height)/2))
+		o.Resize(min)
+		x += min.Width
+	}
+}
+
+func (textRunLayout) MinSize(objects []fyne.CanvasObject) (size fyne.Size) {
+	for _, o := range objects {
+		min := o.MinSize()
+		size.Width += min.Width
+		size.Height = fyne.Max(size.Height, min.Height)
+	}
+	return
+}
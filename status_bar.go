@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+)
+
+const (
+	connLogSize          = 50
+	statusUpdateInterval = 2 * time.Second
+	blockCountInterval   = 30 * time.Second
+)
+
+var connLog = &connLogType{}
+
+type connLogType struct {
+	m       sync.Mutex
+	entries []connLogEntry
+}
+
+type connLogEntry struct {
+	time   time.Time
+	source string
+	err    error
+}
+
+func (l *connLogType) add(source string, err error) {
+	l.m.Lock()
+	l.entries = append(l.entries, connLogEntry{time: time.Now(), source: source, err: err})
+	if len(l.entries) > connLogSize {
+		l.entries = l.entries[len(l.entries)-connLogSize:]
+	}
+	l.m.Unlock()
+}
+
+func (l *connLogType) getEntries() []connLogEntry {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return append([]connLogEntry(nil), l.entries...)
+}
+
+type statusBar struct {
+	widget     fyne.CanvasObject
+	label      *contextMenuLabel
+	m          sync.Mutex
+	node       string
+	blockCount uint64
+	unchecked  uint64
+	countErr   error
+}
+
+func newStatusBar(win fyne.Window) (sb *statusBar) {
+	sb = &statusBar{}
+	sb.label = newContextMenuLabel("RPC: connecting...", fyne.NewMenu("",
+		fyne.NewMenuItem("Diagnostics", func() { sb.showDiagnosticsDialog(win) }),
+		fyne.NewMenuItem("Reconnect", reconnect),
+		fyne.NewMenuItem("Pending outbound", func() { newOutboundWindow() }),
+	))
+	sb.label.tapped = func() { sb.showDiagnosticsDialog(win) }
+	sb.widget = sb.label
+	go sb.loop()
+	return
+}
+
+func (sb *statusBar) loop() {
+	var lastCount time.Time
+	for {
+		if node := nodes.current(); node != "" {
+			sb.m.Lock()
+			changed := node != sb.node
+			sb.m.Unlock()
+			if changed || time.Since(lastCount) >= blockCountInterval {
+				sb.updateBlockCount()
+				lastCount = time.Now()
+			}
+		}
+		sb.label.SetText(sb.text())
+		time.Sleep(statusUpdateInterval)
+	}
+}
+
+func (sb *statusBar) updateBlockCount() {
+	rpcClient := rpc.Client{URL: rpcURL}
+	_, count, unchecked, err := rpcClient.BlockCount()
+	sb.m.Lock()
+	sb.node, sb.blockCount, sb.unchecked, sb.countErr = nodes.current(), count, unchecked, err
+	sb.m.Unlock()
+}
+
+func (sb *statusBar) text() string {
+	sb.m.Lock()
+	defer sb.m.Unlock()
+	var rpcStatus string
+	switch {
+	case !nodes.isReady():
+		rpcStatus = "RPC: connecting..."
+	case nodes.isOffline():
+		rpcStatus = "RPC: offline, showing cached balances"
+	case sb.node == "":
+		rpcStatus = "RPC: " + nodes.current()
+	case sb.countErr != nil:
+		rpcStatus = fmt.Sprintf("RPC: %s (error)", sb.node)
+	default:
+		rpcStatus = fmt.Sprintf("RPC: %s (blocks %d, unchecked %d)", sb.node, sb.blockCount, sb.unchecked)
+	}
+	wsStatus := "Websocket: connecting..."
+	if url, connected := wsClient.status(); connected {
+		wsStatus = "Websocket: " + url + " (live)"
+	} else if url != "" {
+		wsStatus = "Websocket: " + url + " (disconnected, balances may be stale)"
+	}
+	text := rpcStatus + "    " + wsStatus
+	if n := outbound.count(); n > 0 {
+		text += fmt.Sprintf("    Outbound: %d unconfirmed", n)
+	}
+	return text
+}
+
+func (sb *statusBar) showDiagnosticsDialog(win fyne.Window) {
+	var (
+		entries = connLog.getEntries()
+		lines   = make([]string, len(entries))
+		entry   = widget.NewMultiLineEntry()
+		scroll  = container.NewScroll(entry)
+	)
+	for i, e := range entries {
+		lines[len(entries)-1-i] = fmt.Sprintf("%s  %s: %v", e.time.Format("15:04:05"), e.source, e.err)
+	}
+	if len(lines) == 0 {
+		lines = []string{"No errors"}
+	}
+	entry.SetText(strings.Join(lines, "\n"))
+	scroll.SetMinSize(fyne.NewSize(800, 300))
+	content := container.NewBorder(
+		widget.NewLabel(strings.Replace(sb.text(), "    ", "\n", 1)),
+		widget.NewButton("Reconnect", reconnect),
+		nil, nil,
+		container.NewBorder(widget.NewLabel("Recent errors:"), nil, nil, nil, scroll),
+	)
+	dialog.ShowCustom("Connection Diagnostics", "Close", content, win)
+}
+
+// reconnect probes the RPC nodes again and restarts the websocket.
+func reconnect() {
+	go chooseRPC()
+	wsClient.forceReconnect()
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/layout"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+)
+
+type sweepAccount struct {
+	ai       *accountInfo
+	selected bool
+	status   string
+	hash     rpc.BlockHash
+	amount   *big.Int
+}
+
+type sweepWindow struct {
+	wl          *walletList
+	wi          *walletInfo
+	list        *widget.List
+	destination *widget.Entry
+	sweepButton *widget.Button
+	progress    *widget.ProgressBar
+	accounts    []*sweepAccount
+	running     bool
+}
+
+// newSweepWindow consolidates the balances of a wallet's accounts into a
+// single destination account.
+func newSweepWindow(parent fyne.Window, wl *walletList, wi *walletInfo) {
+	if wi.w == nil {
+		dialog.ShowError(errors.New("Select the wallet to unlock it first"), parent)
+		return
+	}
+	win := fyne.CurrentApp().NewWindow("Sweep " + wi.Label)
+	sw := &sweepWindow{
+		wl:          wl,
+		wi:          wi,
+		destination: widget.NewEntry(),
+		progress:    widget.NewProgressBar(),
+	}
+	for _, ai := range wi.accountsList {
+		sw.accounts = append(sw.accounts, &sweepAccount{ai: ai, selected: true})
+	}
+	sw.list = widget.NewList(
+		func() int { return len(sw.accounts) },
+		func() fyne.CanvasObject {
+			return fyne.NewContainerWithLayout(
+				newHBoxLayout([]int{40, 600, 200, 250}), widget.NewCheck("", nil),
+				newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+				newCopyableLabel(win, ""), newCopyableLabel(win, ""),
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			if id >= len(sw.accounts) {
+				return
+			}
+			sa := sw.accounts[id]
+			objects := item.(*fyne.Container).Objects
+			check := objects[0].(*widget.Check)
+			check.OnChanged = nil
+			check.SetChecked(sa.selected)
+			check.OnChanged = func(checked bool) { sa.selected = checked }
+			if sw.running {
+				check.Disable()
+			} else {
+				check.Enable()
+			}
+			objects[1].(*contextMenuLabel).SetText(sa.ai.address)
+			objects[2].(*contextMenuLabel).SetText(sa.ai.Label)
+			wl.al.m.Lock()
+			balance := sa.ai.balance
+			wl.al.m.Unlock()
+			if balance.Raw != nil {
+				objects[3].(*contextMenuLabel).SetText(balance.String() + " NANO")
+			} else {
+				objects[3].(*contextMenuLabel).SetText("")
+			}
+			objects[4].(*contextMenuLabel).SetText(sa.status)
+		},
+	)
+	var (
+		errLabel, isValid = newAddressError()
+		contacts          = widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+			showContactPicker(win, func(c *contact) { sw.destination.SetText(c.Address) })
+		})
+		own = newContextMenuButton("", theme.HomeIcon(), sw.ownAccountsMenu())
+	)
+	sw.destination.SetPlaceHolder("Destination address")
+	sw.sweepButton = widget.NewButtonWithIcon("Sweep", theme.MailForwardIcon(), func() {
+		dest, err := validateAddress(sw.destination.Text)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		dialog.ShowConfirm("Sweep",
+			"Receive pending amounts on the selected accounts and send their entire balances to "+dest+"?",
+			func(ok bool) {
+				if ok {
+					go sw.run(win, dest)
+				}
+			}, win)
+	})
+	sw.destination.OnChanged = func(s string) {
+		if isValid(s) && !sw.running {
+			sw.sweepButton.Enable()
+		} else {
+			sw.sweepButton.Disable()
+		}
+	}
+	sw.sweepButton.Disable()
+	win.SetContent(container.NewBorder(
+		widget.NewForm(widget.NewFormItem("Destination", container.NewVBox(
+			container.NewBorder(nil, nil, nil, container.NewHBox(contacts, own), sw.destination),
+			errLabel,
+		))),
+		container.NewVBox(sw.progress, widget.NewHBox(
+			widget.NewButton("Select All", func() { sw.selectAll(true) }),
+			widget.NewButton("Select None", func() { sw.selectAll(false) }),
+			layout.NewSpacer(), sw.sweepButton,
+		)),
+		nil, nil, sw.list,
+	))
+	win.Resize(fyne.NewSize(1400, 600))
+	win.CenterOnScreen()
+	win.Show()
+}
+
+// ownAccountsMenu lists the accounts of all wallets as destinations.
+func (sw *sweepWindow) ownAccountsMenu() *fyne.Menu {
+	var items []*fyne.MenuItem
+	for _, wi := range sw.wl.wallets {
+		accounts := make([]*accountInfo, 0, len(wi.Accounts))
+		for _, ai := range wi.Accounts {
+			accounts = append(accounts, ai)
+		}
+		sort.Slice(accounts, func(i, j int) bool { return accounts[i].Index < accounts[j].Index })
+		for _, ai := range accounts {
+			ai := ai
+			text := wi.Label + ": " + ai.address
+			if ai.Label != "" {
+				text += " (" + ai.Label + ")"
+			}
+			items = append(items, fyne.NewMenuItem(text, func() { sw.destination.SetText(ai.address) }))
+		}
+	}
+	return fyne.NewMenu("", items...)
+}
+
+func (sw *sweepWindow) selectAll(selected bool) {
+	if sw.running {
+		return
+	}
+	for _, sa := range sw.accounts {
+		sa.selected = selected
+	}
+	sw.list.Refresh()
+}
+
+func (sw *sweepWindow) setStatus(sa *sweepAccount, status string) {
+	sa.status = status
+	sw.list.Refresh()
+}
+
+// run sweeps each selected account in turn. A failure on one account is
+// recorded against it and the sweep carries on with the next.
+func (sw *sweepWindow) run(win fyne.Window, dest string) {
+	sw.running = true
+	sw.sweepButton.Disable()
+	defer func() {
+		sw.running = false
+		sw.sweepButton.Enable()
+		sw.list.Refresh()
+	}()
+	var todo []*sweepAccount
+	for _, sa := range sw.accounts {
+		sa.status, sa.hash, sa.amount = "", nil, nil
+		if sa.selected && sa.ai.address != dest {
+			todo = append(todo, sa)
+		}
+	}
+	sw.progress.Max = float64(len(todo))
+	sw.progress.SetValue(0)
+	for i, sa := range todo {
+		if err := sw.sweep(sa, dest); err != nil {
+			sw.setStatus(sa, "Failed: "+err.Error())
+		}
+		sw.progress.SetValue(float64(i + 1))
+	}
+	sw.showSummary(win, dest, todo)
+}
+
+func (sw *sweepWindow) sweep(sa *sweepAccount, dest string) (err error) {
+	a, err := sw.wi.w.NewAccount(&sa.ai.Index)
+	if err != nil {
+		return
+	}
+	if a.Address() != sa.ai.address {
+		return errors.New("Address mismatch")
+	}
+	sw.setStatus(sa, "Receiving...")
+	receive, _, err := sw.wi.getPendings([]*accountInfo{sa.ai})
+	if err != nil {
+		return
+	}
+	if err = sw.wi.receivePendings(receive); err != nil {
+		return
+	}
+	return withAccountLock(sa.ai.address, func() (err error) {
+		rpcClient := rpc.Client{URL: rpcURL}
+		info, err := rpcClient.AccountInfo(sa.ai.address)
+		if err != nil {
+			if len(receive) == 0 {
+				sw.setStatus(sa, "Nothing to sweep")
+				return nil
+			}
+			return
+		}
+		if info.Balance.Sign() == 0 {
+			sw.setStatus(sa, "Nothing to sweep")
+			return
+		}
+		sw.setStatus(sa, "Sending...")
+		if sa.hash, err = a.Send(dest, &info.Balance.Int); err != nil {
+			return
+		}
+		sa.amount = &info.Balance.Int
+		sw.setStatus(sa, "Swept "+rawToNano(sa.amount)+" NANO")
+		return
+	})
+}
+
+func (sw *sweepWindow) showSummary(win fyne.Window, dest string, swept []*sweepAccount) {
+	var (
+		lines  []string
+		total  = new(big.Int)
+		failed int
+	)
+	for _, sa := range swept {
+		switch {
+		case sa.hash != nil:
+			total.Add(total, sa.amount)
+			lines = append(lines, fmt.Sprintf("%s %s %s", sa.ai.address, rawToNano(sa.amount), sa.hash))
+		case strings.HasPrefix(sa.status, "Failed"):
+			failed++
+		}
+	}
+	text := fmt.Sprintf("Swept %s NANO from %d accounts to %s.", rawToNano(total), len(lines), dest)
+	if failed > 0 {
+		text += fmt.Sprintf(" %d accounts failed.", failed)
+	}
+	hashes := widget.NewMultiLineEntry()
+	hashes.SetText(strings.Join(lines, "\n"))
+	scroll := container.NewScroll(hashes)
+	scroll.SetMinSize(fyne.NewSize(1200, 200))
+	dialog.ShowCustom("Sweep Complete", "OK", container.NewVBox(widget.NewLabel(text), scroll), win)
+}
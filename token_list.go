@@ -10,7 +10,6 @@ import (
 	"fyne.io/fyne/dialog"
 	"fyne.io/fyne/theme"
 	"fyne.io/fyne/widget"
-	"github.com/hectorchu/gonano/rpc"
 	"github.com/hectorchu/gonano/wallet"
 	"github.com/hectorchu/nano-token-protocol/tokenchain"
 )
@@ -70,6 +69,9 @@ func newTokenList(wi *walletInfo, ai *accountInfo) (tl *tokenList) {
 	tl.list.OnSelected = func(id widget.ListItemID) { tl.setToken(tcm.getTokens()[id]) }
 	tl.list.OnUnselected = func(id widget.ListItemID) { tl.setToken(nil) }
 	tl.setToken(nil)
+	if wi.IsWatchOnly {
+		tl.newTokenButton.Disable()
+	}
 	win.SetContent(container.NewBorder(
 		widget.NewLabel("Tokens:"),
 		widget.NewHBox(tl.newTokenButton, tl.addTokenButton, tl.transferButton),
@@ -78,18 +80,18 @@ func newTokenList(wi *walletInfo, ai *accountInfo) (tl *tokenList) {
 	win.Resize(fyne.NewSize(1000, 400))
 	win.CenterOnScreen()
 	win.Show()
-	key := wsClient.subscribe(func(block *rpc.Block) {
-		if tcm.isChainAddress(block.Account) {
+	key := bus.subscribe(func(e interface{}) {
+		if _, ok := e.(chainUpdatedEvent); ok {
 			tl.list.Refresh()
 		}
 	})
-	win.SetOnClosed(func() { wsClient.unsubscribe(key) })
+	win.SetOnClosed(func() { bus.unsubscribe(key) })
 	return
 }
 
 func (tl *tokenList) setToken(token *tokenchain.Token) {
 	tl.selectedToken = token
-	if token != nil {
+	if token != nil && !tl.wi.IsWatchOnly {
 		tl.transferButton.Enable()
 	} else {
 		tl.transferButton.Disable()
@@ -156,7 +158,7 @@ func (tl *tokenList) showNewTokenDialog(win fyne.Window) {
 				dialog.ShowError(err, win)
 			}
 			tl.list.Refresh()
-			showSuccessDialog(win, token.Hash())
+			trackConfirmation(token.Hash())
 		}
 	}, win)
 }
@@ -199,17 +201,23 @@ func (tl *tokenList) showTransferDialog(win fyne.Window) {
 		max     = widget.NewButton("Max", func() {
 			amount.SetText(tcm.amountToString(tcm.getBalance(tl.selectedToken, tl.ai.address), tl.selectedToken.Decimals()))
 		})
-		scroll  = container.NewHScroll(amount)
-		content = widget.NewForm(
-			widget.NewFormItem("Recipient", container.NewHScroll(account)),
+		errLabel, isValid = newAddressError()
+		scroll            = container.NewHScroll(amount)
+		content           = widget.NewForm(
+			widget.NewFormItem("Recipient", container.NewVBox(container.NewHScroll(account), errLabel)),
 			widget.NewFormItem("Amount", container.NewHBox(scroll, max)),
 		)
 	)
 	scroll.SetMinSize(fyne.NewSize(500, 0))
 	account.SetPlaceHolder("Address to send to")
 	amount.SetPlaceHolder("Amount of " + tl.selectedToken.Name() + " to send")
-	dialog.ShowCustomConfirm("Send from "+tl.ai.address, "OK", "Cancel", content, func(ok bool) {
+	d := newConfirmDialog("Send from "+tl.ai.address, "OK", "Cancel", content, func(ok bool) {
 		if ok {
+			to, err := validateAddress(account.Text)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
 			amount, err := tcm.amountFromString(amount.Text, tl.selectedToken.Decimals())
 			if err != nil {
 				dialog.ShowError(err, win)
@@ -222,13 +230,16 @@ func (tl *tokenList) showTransferDialog(win fyne.Window) {
 			}
 			prog := dialog.NewProgressInfinite(tl.selectedToken.Name(), "Transferring token...", win)
 			prog.Show()
-			hash, err := tcm.transferToken(tl.selectedToken, a, account.Text, amount)
+			hash, err := tcm.transferToken(tl.selectedToken, a, to, amount)
 			prog.Hide()
 			if err != nil {
 				dialog.ShowError(err, win)
 				return
 			}
-			showSuccessDialog(win, hash)
+			trackConfirmation(hash)
 		}
 	}, win)
+	account.OnChanged = func(s string) { d.setValid(isValid(s)) }
+	d.setValid(false)
+	d.Show()
 }
@@ -51,6 +51,16 @@ func (tcm *tokenChainManager) getBalance(token *tokenchain.Token, account string
 
 func (tcm *tokenChainManager) createToken(
 	chain *tokenchain.Chain, a *wallet.Account, name string, supply *big.Int, decimals byte,
+) (token *tokenchain.Token, err error) {
+	err = withAccountLock(a.Address(), func() (err error) {
+		token, err = tcm.createTokenLocked(chain, a, name, supply, decimals)
+		return
+	})
+	return
+}
+
+func (tcm *tokenChainManager) createTokenLocked(
+	chain *tokenchain.Chain, a *wallet.Account, name string, supply *big.Int, decimals byte,
 ) (token *tokenchain.Token, err error) {
 	if chain == nil {
 		if chain, err = tokenchain.NewChain(rpcURL); err != nil {
@@ -65,6 +75,7 @@ func (tcm *tokenChainManager) createToken(
 		tcm.m.Lock()
 		tcm.chains[chain.Address()] = chain
 		tcm.m.Unlock()
+		tcm.watchChains()
 	}
 	client := rpc.Client{URL: rpcURL}
 	rep, err := client.AccountRepresentative(a.Address())
@@ -92,13 +103,16 @@ func (tcm *tokenChainManager) transferToken(
 	if err != nil {
 		return
 	}
-	tcm.m.Lock()
-	hash, err = token.Transfer(a, account, amount)
-	tcm.m.Unlock()
-	if err != nil {
+	err = withAccountLock(a.Address(), func() (err error) {
+		tcm.m.Lock()
+		hash, err = token.Transfer(a, account, amount)
+		tcm.m.Unlock()
+		if err != nil {
+			return
+		}
+		_, err = a.ChangeRep(rep)
 		return
-	}
-	_, err = a.ChangeRep(rep)
+	})
 	return
 }
 
@@ -116,6 +130,7 @@ func (tcm *tokenChainManager) fetchChain(address string) (chain *tokenchain.Chai
 		err = tcm.withDB(chain.SaveState)
 	}
 	tcm.m.Unlock()
+	tcm.watchChains()
 	return
 }
 
@@ -143,6 +158,17 @@ func (tcm *tokenChainManager) fetchToken(hash rpc.BlockHash) (token *tokenchain.
 	return
 }
 
+// watchChains subscribes to confirmations for the tracked chain accounts.
+func (tcm *tokenChainManager) watchChains() {
+	tcm.m.Lock()
+	addresses := make([]string, 0, len(tcm.chains))
+	for address := range tcm.chains {
+		addresses = append(addresses, address)
+	}
+	tcm.m.Unlock()
+	wsClient.watch(tcm, addresses)
+}
+
 func (tcm *tokenChainManager) isChainAddress(address string) (ok bool) {
 	tcm.m.Lock()
 	_, ok = tcm.chains[address]
@@ -167,30 +193,46 @@ func (tcm *tokenChainManager) withDB(cb func(*sql.DB) error) (err error) {
 
 func (tcm *tokenChainManager) load() (err error) {
 	tcm.withDB(tcm.loadChains)
+	tcm.watchChains()
 	if err = tcm.loadTokens(); err != nil {
 		return
 	}
-	wsClient.subscribe(func(block *rpc.Block) {
-		tcm.m.Lock()
-		if chain, ok := tcm.chains[block.Account]; ok {
-			if chain.Parse() == nil {
-				tcm.withDB(chain.SaveState)
-			}
+	bus.subscribe(func(e interface{}) {
+		if e, ok := e.(blockConfirmedEvent); ok {
+			tcm.parseChain(e.block.Account)
 		}
-		tcm.m.Unlock()
 	})
-	go func() {
-		tcm.m.Lock()
-		for _, chain := range tcm.chains {
-			if chain.Parse() == nil {
-				tcm.withDB(chain.SaveState)
-			}
-		}
-		tcm.m.Unlock()
-	}()
+	wsClient.onReconnect(tcm.parseChains)
+	nodes.onOnline(tcm.parseChains)
+	go tcm.parseChains()
 	return
 }
 
+func (tcm *tokenChainManager) parseChain(address string) {
+	tcm.m.Lock()
+	chain, ok := tcm.chains[address]
+	parsed := ok && chain.Parse() == nil
+	if parsed {
+		tcm.withDB(chain.SaveState)
+	}
+	tcm.m.Unlock()
+	if parsed {
+		bus.publish(chainUpdatedEvent{address: address})
+	}
+}
+
+func (tcm *tokenChainManager) parseChains() {
+	tcm.m.Lock()
+	addresses := make([]string, 0, len(tcm.chains))
+	for address := range tcm.chains {
+		addresses = append(addresses, address)
+	}
+	tcm.m.Unlock()
+	for _, address := range addresses {
+		tcm.parseChain(address)
+	}
+}
+
 func (tcm *tokenChainManager) loadChains(db *sql.DB) (err error) {
 	rows, err := db.Query("SELECT seed FROM chains")
 	if err != nil {
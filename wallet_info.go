@@ -2,7 +2,9 @@ package main
 
 import (
 	"encoding/hex"
+	"errors"
 	"sort"
+	"time"
 
 	"fyne.io/fyne"
 	"fyne.io/fyne/dialog"
@@ -17,6 +19,9 @@ type walletInfo struct {
 	Label             string
 	Seed, Salt        string
 	IsBip39, IsLedger bool
+	IsWatchOnly       bool
+	AutoReceive       bool
+	ReceiveMinimum    string
 	Accounts          map[string]*accountInfo
 	accountsList      []*accountInfo
 }
@@ -24,13 +29,19 @@ type walletInfo struct {
 type accountInfo struct {
 	address          string
 	Index            uint32
+	Label, Note      string
+	ReceiveMinimum   string
 	balance, pending util.NanoAmount
+	updated          time.Time
 }
 
 func (wi *walletInfo) init(password string) (err error) {
-	if wi.w != nil {
+	if wi.w != nil || wi.IsWatchOnly && wi.accountsList != nil {
 		return
 	}
+	if wi.IsWatchOnly {
+		return wi.initAccountsList()
+	}
 	if wi.IsLedger {
 		if err = wi.initLedger(); err != nil {
 			return
@@ -69,9 +80,20 @@ func (wi *walletInfo) decryptSeed(password string) (seed []byte, err error) {
 	return decrypt(enc, key)
 }
 
+// needsPassword reports whether the wallet's seed is encrypted with a
+// non-empty password.
+func (wi *walletInfo) needsPassword() bool {
+	if wi.IsLedger || wi.IsWatchOnly {
+		return false
+	}
+	_, err := wi.decryptSeed("")
+	return err != nil
+}
+
 func (wi *walletInfo) initSeed(seed []byte) (err error) {
 	wi.w, err = wallet.NewWallet(seed)
 	wi.w.RPC.URL = rpcURL
+	wi.w.RPCWork.URL = workURL
 	return
 }
 
@@ -82,27 +104,41 @@ func (wi *walletInfo) initBip39(entropy []byte, password string) (err error) {
 	}
 	wi.w, err = wallet.NewBip39Wallet(mnemonic, password)
 	wi.w.RPC.URL = rpcURL
+	wi.w.RPCWork.URL = workURL
 	return
 }
 
 func (wi *walletInfo) initLedger() (err error) {
 	wi.w, err = wallet.NewLedgerWallet()
 	wi.w.RPC.URL = rpcURL
+	wi.w.RPCWork.URL = workURL
 	return
 }
 
 func (wi *walletInfo) initAccounts(win fyne.Window) (err error) {
-	prog := dialog.NewProgressInfinite(wi.Label, "Scanning for accounts...", win)
-	prog.Show()
-	err = wi.w.ScanForAccounts()
-	prog.Hide()
-	if err != nil {
-		return
+	var accounts []*wallet.Account
+	if nodes.isOffline() {
+		// Without a node there is no way to tell which accounts are in use,
+		// so start with the first account, derived locally.
+		a, err := wi.w.NewAccount(nil)
+		if err != nil {
+			return err
+		}
+		accounts = append(accounts, a)
+	} else {
+		prog := dialog.NewProgressInfinite(wi.Label, "Scanning for accounts...", win)
+		prog.Show()
+		err = wi.w.ScanForAccounts()
+		prog.Hide()
+		if err != nil {
+			return
+		}
+		accounts = wi.w.GetAccounts()
 	}
 	if wi.Accounts == nil {
 		wi.Accounts = make(map[string]*accountInfo)
 	}
-	for _, a := range wi.w.GetAccounts() {
+	for _, a := range accounts {
 		wi.Accounts[a.Address()] = &accountInfo{
 			address: a.Address(),
 			Index:   a.Index(),
@@ -119,9 +155,19 @@ func (wi *walletInfo) initAccountsList() (err error) {
 	sort.Slice(wi.accountsList, func(i, j int) bool {
 		return wi.accountsList[i].Index < wi.accountsList[j].Index
 	})
+	wi.watchAccounts()
 	return wi.getBalances()
 }
 
+// watchAccounts subscribes to confirmations for the wallet's accounts.
+func (wi *walletInfo) watchAccounts() {
+	accounts := make([]string, len(wi.accountsList))
+	for i, ai := range wi.accountsList {
+		accounts[i] = ai.address
+	}
+	wsClient.watch(wi, accounts)
+}
+
 func (wi *walletInfo) indexOf(ai *accountInfo) int {
 	return sort.Search(len(wi.accountsList), func(i int) bool {
 		return wi.accountsList[i].Index >= ai.Index
@@ -150,14 +196,37 @@ func (wi *walletInfo) addAccount() (err error) {
 		wi.accountsList = append(wi.accountsList[:i+1], wi.accountsList[i:]...)
 		wi.accountsList[i] = ai
 	}
+	wi.watchAccounts()
 	wi.updateBalance(ai.address)
 	return
 }
 
+func (wi *walletInfo) addWatchAccount(address string) (err error) {
+	pubkey, err := util.AddressToPubkey(address)
+	if err != nil {
+		return
+	}
+	if address, err = util.PubkeyToAddress(pubkey); err != nil {
+		return
+	}
+	if _, ok := wi.Accounts[address]; ok {
+		return errors.New("Account already exists")
+	}
+	ai := &accountInfo{address: address}
+	if n := len(wi.accountsList); n > 0 {
+		ai.Index = wi.accountsList[n-1].Index + 1
+	}
+	wi.Accounts[address] = ai
+	wi.accountsList = append(wi.accountsList, ai)
+	wi.watchAccounts()
+	return
+}
+
 func (wi *walletInfo) removeAccount(ai *accountInfo) {
 	delete(wi.Accounts, ai.address)
 	i := wi.indexOf(ai)
 	wi.accountsList = append(wi.accountsList[:i], wi.accountsList[i+1:]...)
+	wi.watchAccounts()
 }
 
 func (wi *walletInfo) getBalances() (err error) {
@@ -171,15 +240,63 @@ func (wi *walletInfo) getBalances() (err error) {
 	rpcClient := rpc.Client{URL: rpcURL}
 	balances, err := rpcClient.AccountsBalances(accounts)
 	if err != nil {
-		return
+		return wi.loadCachedBalances(accounts, err)
 	}
+	frontiers, _ := rpcClient.AccountsFrontiers(accounts)
+	cache := make(map[string]*cachedAccount)
 	for address, ab := range balances {
 		ai := wi.Accounts[address]
 		ai.balance.Raw, ai.pending.Raw = &ab.Balance.Int, &ab.Pending.Int
+		ai.updated = time.Time{}
+		cache[address] = &cachedAccount{
+			balance:  &ab.Balance.Int,
+			pending:  &ab.Pending.Int,
+			frontier: frontiers[address],
+			updated:  time.Now(),
+		}
 	}
+	saveCachedAccounts(cache)
 	return
 }
 
+// loadCachedBalances fills in the last known balances when no node can be
+// reached. If nothing has been cached, balances are left empty when offline
+// and rpcErr is returned otherwise.
+func (wi *walletInfo) loadCachedBalances(accounts []string, rpcErr error) (err error) {
+	cache, err := loadCachedAccounts(accounts)
+	if err != nil || len(cache) == 0 {
+		if nodes.isOffline() {
+			return nil
+		}
+		return rpcErr
+	}
+	for address, ca := range cache {
+		ai := wi.Accounts[address]
+		ai.balance.Raw, ai.pending.Raw = ca.balance, ca.pending
+		ai.updated = ca.updated
+	}
+	return
+}
+
+// publishBalance fetches the balance of account and publishes it on the
+// event bus. frontier is the account's latest block, if known.
+func publishBalance(account string, frontier rpc.BlockHash) {
+	rpcClient := rpc.Client{URL: rpcURL}
+	if balance, pending, err := rpcClient.AccountBalance(account); err == nil {
+		saveCachedAccounts(map[string]*cachedAccount{account: {
+			balance:  &balance.Int,
+			pending:  &pending.Int,
+			frontier: frontier,
+			updated:  time.Now(),
+		}})
+		bus.publish(balanceChangedEvent{
+			account: account,
+			balance: util.NanoAmount{Raw: &balance.Int},
+			pending: util.NanoAmount{Raw: &pending.Int},
+		})
+	}
+}
+
 func (wi *walletInfo) updateBalance(account string) (updated bool) {
 	if ai, ok := wi.Accounts[account]; ok {
 		rpcClient := rpc.Client{URL: rpcURL}
@@ -52,6 +52,27 @@ func newWalletList(win fyne.Window, al *accountList) (wl *walletList) {
 						}))
 					}
 				}
+				if !wi.IsWatchOnly && !wi.IsLedger {
+					toggle := "Enable auto-receive"
+					if wi.AutoReceive {
+						toggle = "Disable auto-receive"
+					}
+					items = append(items, fyne.NewMenuItem(toggle, func() {
+						wi.AutoReceive = !wi.AutoReceive
+						wl.list.Refresh()
+						if err := wl.saveWallet(wi); err != nil {
+							dialog.ShowError(err, win)
+						}
+					}))
+				}
+				if !wi.IsWatchOnly {
+					items = append(items,
+						fyne.NewMenuItem("Minimum receive amount", func() {
+							showReceiveMinimumDialog(win, wl, wi, nil)
+						}),
+						fyne.NewMenuItem("Sweep", func() { newSweepWindow(win, wl, wi) }),
+					)
+				}
 				l.menu = fyne.NewMenu("", items...)
 			},
 		),
@@ -63,10 +84,15 @@ func newWalletList(win fyne.Window, al *accountList) (wl *walletList) {
 					dialog.ShowError(err, win)
 				}
 			}),
+			fyne.NewMenuItem("Watch-only", func() { wl.showWatchOnlyWalletDialog(win) }),
 		)),
 		removeButton: widget.NewButtonWithIcon("Remove", theme.ContentRemoveIcon(), func() {
+			msg := "You will only be able to restore from seed/mnemonic."
+			if wl.selectedWallet.IsWatchOnly {
+				msg = "The watched addresses will be forgotten."
+			}
 			dialog.ShowConfirm(
-				"Are you sure?", "You will only be able to restore from seed/mnemonic.", func(ok bool) {
+				"Are you sure?", msg, func(ok bool) {
 					if ok {
 						if err := wl.removeWallet(wl.selectedWallet); err != nil {
 							dialog.ShowError(err, win)
@@ -86,6 +112,7 @@ func newWalletList(win fyne.Window, al *accountList) (wl *walletList) {
 	wl.list.OnUnselected = func(id widget.ListItemID) { wl.setWallet(win, nil) }
 	wl.setWallet(win, nil)
 	wl.initWallets()
+	bus.subscribe(wl.autoReceive)
 	return
 }
 
@@ -102,19 +129,26 @@ func (wl *walletList) initWallets() {
 			return fmt.Sprintf("wallets.%d.%s", i, s)
 		}
 		wl.wallets[i] = &walletInfo{
-			Label:    viper.GetString(key("label")),
-			Seed:     viper.GetString(key("seed")),
-			Salt:     viper.GetString(key("salt")),
-			IsBip39:  viper.GetBool(key("isBip39")),
-			IsLedger: viper.GetBool(key("isLedger")),
-			Accounts: make(map[string]*accountInfo),
+			Label:          viper.GetString(key("label")),
+			Seed:           viper.GetString(key("seed")),
+			Salt:           viper.GetString(key("salt")),
+			IsBip39:        viper.GetBool(key("isBip39")),
+			IsLedger:       viper.GetBool(key("isLedger")),
+			IsWatchOnly:    viper.GetBool(key("isWatchOnly")),
+			AutoReceive:    viper.GetBool(key("autoReceive")),
+			ReceiveMinimum: viper.GetString(key("receiveMinimum")),
+			Accounts:       make(map[string]*accountInfo),
 		}
 		for k, v := range viper.GetStringMap(key("accounts")) {
 			v := v.(map[string]interface{})
-			wl.wallets[i].Accounts[k] = &accountInfo{
+			ai := &accountInfo{
 				address: k,
 				Index:   uint32(v["index"].(int)),
 			}
+			ai.Label, _ = v["label"].(string)
+			ai.Note, _ = v["note"].(string)
+			ai.ReceiveMinimum, _ = v["receiveminimum"].(string)
+			wl.wallets[i].Accounts[k] = ai
 		}
 	}
 }
@@ -146,7 +180,11 @@ func (wl *walletList) setWallet(win fyne.Window, wi *walletInfo) {
 			return
 		}
 		if err := init(""); err != nil {
-			showPasswordDialog(win, wi.Label, init)
+			if wi.needsPassword() {
+				showPasswordDialog(win, wi.Label, init)
+			} else {
+				dialog.ShowError(err, win)
+			}
 		}
 	} else {
 		wl.removeButton.Disable()
@@ -174,10 +212,21 @@ func (wl *walletList) showRenameDialog(win fyne.Window, wi *walletInfo) {
 	}, win)
 }
 
+// findAccount returns the wallet containing address, if any.
+func (wl *walletList) findAccount(address string) *walletInfo {
+	for _, wi := range wl.wallets {
+		if _, ok := wi.Accounts[address]; ok {
+			return wi
+		}
+	}
+	return nil
+}
+
 func (wl *walletList) removeWallet(wi *walletInfo) (err error) {
 	for i := range wl.wallets {
 		if wi == wl.wallets[i] {
 			wl.wallets = append(wl.wallets[:i], wl.wallets[i+1:]...)
+			wsClient.watch(wi, nil)
 			wl.list.Unselect(i)
 			wl.list.Refresh()
 			for ; i < len(wl.wallets); i++ {
@@ -358,6 +407,64 @@ func (wl *walletList) newLedgerWallet(win fyne.Window) (err error) {
 	return wl.saveWallet(wi)
 }
 
+func (wl *walletList) showWatchOnlyWalletDialog(win fyne.Window) {
+	var (
+		label             = widget.NewEntry()
+		addresses         = widget.NewMultiLineEntry()
+		scroll            = container.NewHScroll(label)
+		scroll2           = container.NewScroll(addresses)
+		errLabel, isValid = newAddressError()
+		content           = widget.NewForm(
+			widget.NewFormItem("Label", scroll),
+			widget.NewFormItem("Addresses", container.NewVBox(scroll2, errLabel)),
+		)
+	)
+	scroll.SetMinSize(fyne.NewSize(600, 0))
+	scroll2.SetMinSize(fyne.NewSize(600, 200))
+	label.SetText(fmt.Sprintf("Watch-only Wallet #%d", len(wl.wallets)+1))
+	addresses.SetPlaceHolder("One nano_ address per line")
+	d := newConfirmDialog("New Watch-only Wallet", "OK", "Cancel", content, func(ok bool) {
+		if ok {
+			if err := wl.newWatchOnlyWallet(label.Text, addresses.Text); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}
+	}, win)
+	// Every address must be valid; the first invalid one is reported.
+	addresses.OnChanged = func(s string) {
+		fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+		valid := isValid("")
+		for _, address := range fields {
+			if valid = isValid(address); !valid {
+				break
+			}
+		}
+		d.setValid(valid)
+	}
+	d.setValid(false)
+	d.Show()
+}
+
+func (wl *walletList) newWatchOnlyWallet(label, addresses string) (err error) {
+	wi := &walletInfo{
+		Label:       label,
+		IsWatchOnly: true,
+		Accounts:    make(map[string]*accountInfo),
+	}
+	for _, address := range strings.Fields(strings.ReplaceAll(addresses, ",", " ")) {
+		if err = wi.addWatchAccount(address); err != nil {
+			wsClient.watch(wi, nil)
+			return fmt.Errorf("%s: %v", address, err)
+		}
+	}
+	if len(wi.Accounts) == 0 {
+		return errors.New("No addresses given")
+	}
+	wl.wallets = append(wl.wallets, wi)
+	wl.list.Refresh()
+	return wl.saveWallet(wi)
+}
+
 func (wl *walletList) exportSeed(win fyne.Window, wi *walletInfo) {
 	if seed, err := wi.decryptSeed(""); err == nil {
 		wl.exportSeedDialog(win, wi, seed)
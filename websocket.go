@@ -1,75 +1,246 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/hectorchu/gonano/rpc"
-	"github.com/hectorchu/gonano/websocket"
+	"github.com/hectorchu/gonano/util"
 )
 
+const wsRetryInterval = 10 * time.Second
+
+var wsURLs = []string{
+	"wss://gonano.dev/ws",
+	"wss://ws.mynano.ninja",
+	"wss://ws.powernode.cc",
+	"wss://rainstorm.city/websocket",
+}
+
 type wsClientType struct {
-	m   sync.Mutex
-	key int
-	sub []wsClientSub
+	m          sync.Mutex
+	resync     []func()
+	sm         sync.Mutex
+	url        string
+	conn       *websocket.Conn
+	accounts   map[interface{}][]string
+	subscribed map[string]bool
+	reconnect  chan bool
 }
 
-type wsClientSub struct {
-	key int
-	f   func(*rpc.Block)
+var wsClient = wsClientType{
+	accounts:  make(map[interface{}][]string),
+	reconnect: make(chan bool, 1),
+}
+
+// onReconnect registers f to be called after each reconnect, to catch up on
+// any confirmations that were missed while disconnected.
+func (c *wsClientType) onReconnect(f func()) {
+	c.m.Lock()
+	c.resync = append(c.resync, f)
+	c.m.Unlock()
 }
 
-var wsClient = wsClientType{}
+// watch sets the accounts that owner wants confirmations for. The server
+// only sends confirmations for blocks on, or sent to, the union of all the
+// watched accounts. A nil list removes owner.
+func (c *wsClientType) watch(owner interface{}, accounts []string) {
+	c.sm.Lock()
+	if accounts == nil {
+		delete(c.accounts, owner)
+	} else {
+		c.accounts[owner] = append([]string(nil), accounts...)
+	}
+	c.updateFilter()
+	c.sm.Unlock()
+}
 
-func init() {
-	for _, url := range []string{
-		"wss://gonano.dev/ws",
-		"wss://ws.mynano.ninja",
-		"wss://ws.powernode.cc",
-		"wss://rainstorm.city/websocket",
-	} {
-		ws := &websocket.Client{URL: url}
-		if ws.Connect() == nil {
-			go wsClient.loop(ws)
-			return
+// updateFilter brings the server's account filter in line with the watched
+// accounts. It must be called with c.sm held.
+func (c *wsClientType) updateFilter() {
+	if c.conn == nil {
+		return
+	}
+	accounts := make(map[string]bool)
+	for _, list := range c.accounts {
+		for _, account := range list {
+			accounts[account] = true
+		}
+	}
+	var add, del []string
+	for account := range accounts {
+		if !c.subscribed[account] {
+			add = append(add, account)
+		}
+	}
+	for account := range c.subscribed {
+		if !accounts[account] {
+			del = append(del, account)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(del)
+	var msg interface{}
+	switch {
+	case len(accounts) == 0 && len(c.subscribed) > 0:
+		msg = map[string]interface{}{
+			"action": "unsubscribe",
+			"topic":  "confirmation",
+		}
+	case len(accounts) > 0 && len(c.subscribed) == 0:
+		msg = map[string]interface{}{
+			"action":  "subscribe",
+			"topic":   "confirmation",
+			"options": map[string]interface{}{"accounts": add},
 		}
+	case len(add) > 0 || len(del) > 0:
+		msg = map[string]interface{}{
+			"action": "update",
+			"topic":  "confirmation",
+			"options": map[string]interface{}{
+				"accounts_add": add,
+				"accounts_del": del,
+			},
+		}
+	default:
+		return
+	}
+	if err := c.conn.WriteJSON(msg); err != nil {
+		connLog.add(c.url, err)
+		c.conn.Close()
+		return
 	}
+	c.subscribed = accounts
 }
 
-func (c *wsClientType) subscribe(f func(*rpc.Block)) (key int) {
-	c.m.Lock()
-	key = c.key
-	c.key++
-	c.sub = append(c.sub, wsClientSub{key: key, f: f})
-	c.m.Unlock()
-	return
+func (c *wsClientType) status() (url string, connected bool) {
+	c.sm.Lock()
+	defer c.sm.Unlock()
+	return c.url, c.conn != nil
 }
 
-func (c *wsClientType) unsubscribe(key int) {
-	c.m.Lock()
-	for i, sub := range c.sub {
-		if sub.key == key {
-			c.sub = append(c.sub[:i], c.sub[i+1:]...)
-			break
+func (c *wsClientType) setConn(url string, conn *websocket.Conn) {
+	c.sm.Lock()
+	c.url, c.conn, c.subscribed = url, conn, nil
+	c.updateFilter()
+	c.sm.Unlock()
+}
+
+// forceReconnect drops the current connection and dials again.
+func (c *wsClientType) forceReconnect() {
+	c.sm.Lock()
+	if c.conn != nil {
+		c.conn.Close()
+	} else {
+		select {
+		case c.reconnect <- true:
+		default:
 		}
 	}
-	c.m.Unlock()
+	c.sm.Unlock()
 }
 
-func (c *wsClientType) loop(ws *websocket.Client) {
+// run keeps a connection open for the lifetime of the app.
+func (c *wsClientType) run() {
+	connectedBefore := false
 	for {
-		switch m := (<-ws.Messages).(type) {
-		case *websocket.Confirmation:
-			c.m.Lock()
-			for _, sub := range c.sub {
-				sub.f(m.Block)
+		url, conn := c.dial()
+		if conn == nil {
+			connLog.add("websocket", errors.New("No websocket endpoint is reachable"))
+			select {
+			case <-time.After(wsRetryInterval):
+			case <-c.reconnect:
 			}
+			continue
+		}
+		c.setConn(url, conn)
+		if connectedBefore {
+			c.m.Lock()
+			resync := append([]func(){}, c.resync...)
 			c.m.Unlock()
-		case error:
-			ws.Close()
-			for ws.Connect() != nil {
-				time.Sleep(10 * time.Second)
+			for _, f := range resync {
+				go f()
+			}
+		}
+		connectedBefore = true
+		c.loop(url, conn)
+		c.setConn(url, nil)
+		conn.Close()
+	}
+}
+
+// dial connects to all endpoints concurrently and keeps the first one to
+// complete the handshake.
+func (c *wsClientType) dial() (url string, conn *websocket.Conn) {
+	type result struct {
+		url  string
+		conn *websocket.Conn
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	ch := make(chan result, len(wsURLs))
+	for _, url := range wsURLs {
+		go func(url string) {
+			conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+			if err != nil {
+				connLog.add(url, err)
 			}
+			ch <- result{url, conn}
+		}(url)
+	}
+	for i := range wsURLs {
+		if r := <-ch; r.conn != nil {
+			go func(n int) {
+				for ; n > 0; n-- {
+					if r := <-ch; r.conn != nil {
+						r.conn.Close()
+					}
+				}
+			}(len(wsURLs) - i - 1)
+			return r.url, r.conn
+		}
+	}
+	return
+}
+
+// loop publishes the confirmations received on conn to the event bus.
+func (c *wsClientType) loop(url string, conn *websocket.Conn) {
+	for {
+		var m struct {
+			Topic   string
+			Message struct {
+				Hash   rpc.BlockHash
+				Amount *rpc.RawAmount
+				Block  *struct {
+					rpc.Block
+					Subtype string
+				}
+			}
+		}
+		if err := conn.ReadJSON(&m); err != nil {
+			connLog.add(url, err)
+			return
+		}
+		if m.Topic != "confirmation" || m.Message.Block == nil {
+			continue
+		}
+		block := &m.Message.Block.Block
+		bus.publish(blockConfirmedEvent{
+			block:   block,
+			subtype: m.Message.Block.Subtype,
+			hash:    m.Message.Hash,
+		})
+		if m.Message.Block.Subtype == "send" && m.Message.Amount != nil {
+			bus.publish(pendingArrivedEvent{
+				account: block.LinkAsAccount,
+				source:  block.Account,
+				hash:    m.Message.Hash,
+				amount:  util.NanoAmount{Raw: &m.Message.Amount.Int},
+			})
 		}
 	}
 }
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/widget"
+	"github.com/hectorchu/gonano/rpc"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/blake2b"
+)
+
+// workURL is handled by the work generator, which either computes work
+// locally or forwards the request to the chosen work source.
+const workURL = "gonanowork://work"
+
+const (
+	workSourceLocal  = "Local CPU"
+	workSourceNode   = "RPC node"
+	workSourceServer = "Work server"
+)
+
+var (
+	sendDifficulty, _    = hex.DecodeString("fffffff800000000")
+	receiveDifficulty, _ = hex.DecodeString("fffffe0000000000")
+)
+
+var works = &workGenerator{
+	cache:   make(map[string][]byte),
+	cacheBy: make(map[string]string),
+	jobs:    make(map[string]*workJob),
+}
+
+type workGenerator struct {
+	m       sync.Mutex
+	cache   map[string][]byte
+	cacheBy map[string]string
+	jobs    map[string]*workJob
+}
+
+// workJob is a precache search in progress for an account's frontier.
+type workJob struct {
+	hash   string
+	cancel context.CancelFunc
+	done   chan bool
+	work   []byte
+	err    error
+}
+
+func init() {
+	http.DefaultTransport.(*http.Transport).RegisterProtocol("gonanowork", works)
+}
+
+func workSource() string {
+	if s := viper.GetString("work.source"); s != "" {
+		return s
+	}
+	return workSourceLocal
+}
+
+func workThreads() int {
+	if n := viper.GetInt("work.threads"); n > 0 {
+		return n
+	}
+	return runtime.NumCPU()
+}
+
+// RoundTrip implements http.RoundTripper for the gonanowork:// scheme.
+func (wg *workGenerator) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return
+	}
+	var v struct {
+		Action     string
+		Hash       rpc.BlockHash
+		Difficulty rpc.HexData
+	}
+	if err = json.Unmarshal(body, &v); err != nil {
+		return
+	}
+	if v.Action != "work_generate" {
+		return nil, errors.New("Unsupported action " + v.Action)
+	}
+	if len(v.Difficulty) != 8 {
+		v.Difficulty = sendDifficulty
+	}
+	work, err := wg.generate(req.Context(), v.Hash, v.Difficulty)
+	if err != nil {
+		return
+	}
+	if body, err = json.Marshal(map[string]interface{}{
+		"work":       rpc.HexData(work),
+		"difficulty": v.Difficulty,
+		"multiplier": "1",
+	}); err != nil {
+		return
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// generate returns work for hash, using precached work when it meets the
+// difficulty. If work for hash is being precached, that search is waited on
+// rather than starting another.
+func (wg *workGenerator) generate(ctx context.Context, hash, difficulty []byte) (work []byte, err error) {
+	target := binary.BigEndian.Uint64(difficulty)
+	wg.m.Lock()
+	work, ok := wg.cache[string(hash)]
+	var job *workJob
+	for _, j := range wg.jobs {
+		if j.hash == string(hash) {
+			job = j
+		}
+	}
+	wg.m.Unlock()
+	if ok && workValue(hash, work) >= target {
+		return
+	}
+	if job != nil {
+		select {
+		case <-job.done:
+			if job.err == nil && workValue(hash, job.work) >= target {
+				return job.work, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return wg.compute(ctx, hash, difficulty)
+}
+
+// compute generates work for hash from the configured work source.
+func (wg *workGenerator) compute(ctx context.Context, hash, difficulty []byte) (work []byte, err error) {
+	switch workSource() {
+	case workSourceNode:
+		return remoteWork(ctx, rpcURL, hash, difficulty)
+	case workSourceServer:
+		return remoteWork(ctx, viper.GetString("work.server"), hash, difficulty)
+	}
+	return localWork(ctx, hash, binary.BigEndian.Uint64(difficulty), workThreads())
+}
+
+// precache generates work in the background for the next block after
+// frontier on account. Only one search runs per account: a newer frontier
+// cancels the search for the previous one.
+func (wg *workGenerator) precache(account string, frontier rpc.BlockHash) {
+	if viper.GetBool("work.noPrecache") {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &workJob{hash: string(frontier), cancel: cancel, done: make(chan bool)}
+	wg.m.Lock()
+	if _, ok := wg.cache[job.hash]; ok {
+		wg.m.Unlock()
+		cancel()
+		return
+	}
+	if old, ok := wg.jobs[account]; ok {
+		if old.hash == job.hash {
+			wg.m.Unlock()
+			cancel()
+			return
+		}
+		old.cancel()
+	}
+	wg.jobs[account] = job
+	wg.m.Unlock()
+	go func() {
+		defer cancel()
+		job.work, job.err = wg.compute(ctx, frontier, sendDifficulty)
+		wg.m.Lock()
+		if wg.jobs[account] == job {
+			delete(wg.jobs, account)
+			if job.err == nil {
+				delete(wg.cache, wg.cacheBy[account])
+				wg.cache[job.hash] = job.work
+				wg.cacheBy[account] = job.hash
+			}
+		}
+		wg.m.Unlock()
+		close(job.done)
+	}()
+}
+
+func remoteWork(ctx context.Context, url string, hash, difficulty []byte) (work []byte, err error) {
+	if url == "" {
+		return nil, errors.New("No work server configured")
+	}
+	rpcClient := rpc.Client{URL: url, Ctx: ctx}
+	work, _, _, err = rpcClient.WorkGenerate(hash, difficulty)
+	return
+}
+
+// localWork searches for work on the CPU. Work is returned in the byte order
+// used by the RPC protocol, which is the reverse of the order it is hashed in.
+func localWork(ctx context.Context, hash []byte, target uint64, threads int) (work []byte, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan []byte, threads)
+	x := rand.Uint64()
+	for i := 0; i < threads; i++ {
+		go func(x uint64) {
+			h, _ := blake2b.New(8, nil)
+			nonce := make([]byte, 8)
+			for i := 1; ; i, x = i+1, x+uint64(threads) {
+				if i%(1<<16) == 0 && ctx.Err() != nil {
+					return
+				}
+				binary.LittleEndian.PutUint64(nonce, x)
+				h.Reset()
+				h.Write(nonce)
+				h.Write(hash)
+				if binary.LittleEndian.Uint64(h.Sum(nil)) >= target {
+					work := make([]byte, 8)
+					binary.BigEndian.PutUint64(work, x)
+					ch <- work
+					return
+				}
+			}
+		}(x + uint64(i))
+	}
+	select {
+	case work = <-ch:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return
+}
+
+func workValue(hash, work []byte) uint64 {
+	nonce := make([]byte, 8)
+	for i := range work {
+		nonce[i] = work[len(work)-1-i]
+	}
+	h, _ := blake2b.New(8, nil)
+	h.Write(nonce)
+	h.Write(hash)
+	return binary.LittleEndian.Uint64(h.Sum(nil))
+}
+
+func showWorkSettingsDialog(win fyne.Window) {
+	var (
+		source   = widget.NewRadioGroup([]string{workSourceLocal, workSourceNode, workSourceServer}, nil)
+		threads  = widget.NewEntry()
+		server   = widget.NewEntry()
+		precache = widget.NewCheck("Precache work after each confirmed block", nil)
+		scroll   = container.NewHScroll(server)
+		content  = widget.NewForm(
+			widget.NewFormItem("Source", source),
+			widget.NewFormItem("CPU threads", threads),
+			widget.NewFormItem("Work server", scroll),
+			widget.NewFormItem("", precache),
+		)
+	)
+	source.SetSelected(workSource())
+	threads.SetText(strconv.Itoa(workThreads()))
+	server.SetText(viper.GetString("work.server"))
+	server.SetPlaceHolder("https://example.com/work")
+	precache.SetChecked(!viper.GetBool("work.noPrecache"))
+	scroll.SetMinSize(fyne.NewSize(400, 0))
+	dialog.ShowCustomConfirm("Proof of Work", "OK", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		n, err := strconv.Atoi(threads.Text)
+		if err != nil || n < 1 {
+			dialog.ShowError(errors.New("Invalid thread count"), win)
+			return
+		}
+		if source.Selected == workSourceServer {
+			if u, err := url.Parse(server.Text); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				dialog.ShowError(errors.New("Invalid URL"), win)
+				return
+			}
+		}
+		viper.Set("work.source", source.Selected)
+		viper.Set("work.threads", n)
+		viper.Set("work.server", server.Text)
+		viper.Set("work.noPrecache", !precache.Checked)
+		if err := viper.WriteConfig(); err != nil {
+			dialog.ShowError(err, win)
+		}
+	}, win)
+}
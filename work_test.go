@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestWorkValue(t *testing.T) {
+	hash, _ := hex.DecodeString("718CC2121C3E641059BC1C2CFC45666C99E8AE922F7A807B7D07B62C995D79E2")
+	work, _ := hex.DecodeString("2bf29ef00786a6bc")
+	if v := workValue(hash, work); v != 0xffffffd21c3933f4 {
+		t.Errorf("got %016x, want ffffffd21c3933f4", v)
+	}
+}
+
+func TestLocalWork(t *testing.T) {
+	target := binary.BigEndian.Uint64(receiveDifficulty)
+	hash := make([]byte, 32)
+	rand.Read(hash)
+	work, err := localWork(context.Background(), hash, target, runtime.NumCPU())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := workValue(hash, work); v < target {
+		t.Errorf("work %x for %x has value %016x, below %016x", work, hash, v, target)
+	}
+}
+
+func TestLocalWorkCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := localWork(ctx, make([]byte, 32), ^uint64(0), 1); err != context.Canceled {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}